@@ -14,6 +14,7 @@ interface DownloadProgress {
   percent: number
   done: boolean
   error?: string
+  partialKept?: boolean
 }
 
 export function useModelDownload() {
@@ -21,13 +22,17 @@ export function useModelDownload() {
   const [downloading, setDownloading] = useState(false)
   const [progress, setProgress] = useState<DownloadProgress | null>(null)
   const [error, setError] = useState('')
+  // Set when Cancel paused the download; starting it again resumes
+  const [paused, setPaused] = useState(false)
   const unsubRef = useRef<(() => void) | null>(null)
 
   useEffect(() => {
     unsubRef.current = Events.On('model:download-progress', (event) => {
       const p = event.data as DownloadProgress
       if (p.error) {
-        setError(p.error === 'cancelled' ? '' : p.error)
+        const isPause = p.error === 'paused'
+        setPaused(isPause)
+        setError(isPause || p.error === 'cancelled' ? '' : p.error)
         setDownloading(false)
         setProgress(null)
         return
@@ -58,6 +63,7 @@ export function useModelDownload() {
 
   const startDownload = useCallback(async (name: string) => {
     setError('')
+    setPaused(false)
     setProgress(null)
     setDownloading(true)
     try {
@@ -81,6 +87,7 @@ export function useModelDownload() {
     downloading,
     progress,
     error,
+    paused,
     loadModels,
     startDownload,
     cancelDownload,
@@ -52,9 +52,9 @@ export function useRecorder() {
 
   const stopRecording = useCallback(async (): Promise<string> => {
     stopTimer()
-    const wavPath = await AudioService.StopRecording()
+    const recording = await AudioService.StopRecording()
     setState('idle')
-    return wavPath
+    return recording.wavPath
   }, [stopTimer])
 
   const setTranscribing = useCallback(() => {
@@ -7,19 +7,28 @@ import (
 
 	"github.com/dannygim/meeting-transcriber/services"
 	"github.com/wailsapp/wails/v3/pkg/application"
+	"github.com/wailsapp/wails/v3/pkg/events"
 )
 
 //go:embed all:frontend/dist
 var assets embed.FS
 
 func main() {
+	audio := &services.AudioService{}
+	transcribe := &services.TranscribeService{}
+	model := &services.ModelService{}
+
 	app := application.New(application.Options{
 		Name:        "Meeting Transcriber",
 		Description: "On-device meeting audio transcription",
 		Services: []application.Service{
-			application.NewService(&services.AudioService{}),
-			application.NewService(&services.TranscribeService{}),
-			application.NewService(&services.ModelService{}),
+			application.NewService(audio),
+			application.NewService(transcribe),
+			application.NewService(model),
+			application.NewService(services.NewStatusService(audio, transcribe, model)),
+			application.NewService(services.NewLiveService(audio, transcribe)),
+			application.NewService(services.NewWorkflowService(audio, transcribe)),
+			application.NewService(services.NewSettingsService(transcribe)),
 		},
 		Assets: application.AssetOptions{
 			Handler: application.AssetFileServerFS(assets),
@@ -29,7 +38,7 @@ func main() {
 		},
 	})
 
-	app.Window.NewWithOptions(application.WebviewWindowOptions{
+	window := app.Window.NewWithOptions(application.WebviewWindowOptions{
 		Title:  "Meeting Transcriber",
 		Width:  600,
 		Height: 500,
@@ -42,6 +51,13 @@ func main() {
 		URL:              "/",
 	})
 
+	// Release the microphone as soon as the window closes rather than at exit
+	window.RegisterHook(events.Common.WindowClosing, func(_ *application.WindowEvent) {
+		if err := audio.ReleaseAudio(); err != nil {
+			log.Printf("failed to release audio: %v", err)
+		}
+	})
+
 	err := app.Run()
 	if err != nil {
 		log.Fatal(err)
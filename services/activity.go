@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// speechRMSThreshold is the int16 RMS level above which a buffer is
+// considered to contain speech rather than room noise.
+const speechRMSThreshold = 500
+
+// InactivityEvent is emitted as "audio:inactivity" when no speech has been
+// heard for the configured duration while recording.
+type InactivityEvent struct {
+	RecordingID   string  `json:"recordingId"`
+	SilentSeconds float64 `json:"silentSeconds"`
+}
+
+// SetInactivityReminder emits "audio:inactivity" once no speech has been
+// detected for the given number of seconds while recording. The event is
+// advisory only; recording continues. Zero disables the reminder.
+func (a *AudioService) SetInactivityReminder(seconds float64) error {
+	if seconds < 0 {
+		return fmt.Errorf("inactivity reminder must not be negative")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inactivityAfter = time.Duration(seconds * float64(time.Second))
+	a.inactivityNotified = false
+	return nil
+}
+
+// trackActivity updates speech detection from a callback buffer, net of
+// any noise profile, and fires the inactivity reminder. Speech also cancels
+// a pending silence auto-stop. Only called while actively recording, so
+// intentional pauses never count as silence. Callers must hold a.mu.
+func (a *AudioService) trackActivity(in []int16) {
+	now := time.Now()
+	if a.speechLevel(in) >= speechRMSThreshold {
+		a.lastSpeech = now
+		a.inactivityNotified = false
+		if a.autoStopCancel != nil && a.autoStopReason == autoStopReasonSilence {
+			event := a.cancelAutoStop()
+			go application.Get().Event.Emit("audio:auto-stop-cancelled", event)
+		}
+		return
+	}
+
+	if a.inactivityAfter <= 0 || a.inactivityNotified {
+		return
+	}
+	silent := now.Sub(a.lastSpeech)
+	if silent < a.inactivityAfter {
+		return
+	}
+
+	a.inactivityNotified = true
+	// Emit off the audio thread so the callback never waits on the event bus
+	go application.Get().Event.Emit("audio:inactivity", InactivityEvent{RecordingID: a.recordingID, SilentSeconds: silent.Seconds()})
+}
@@ -2,8 +2,8 @@ package services
 
 import (
 	"context"
-	"encoding/binary"
 	"fmt"
+	"log"
 	"math"
 	"os"
 	"path/filepath"
@@ -15,11 +15,25 @@ import (
 )
 
 const (
-	outputSampleRate = 16000 // whisper.cpp expects 16kHz
-	channels         = 1
+	outputSampleRate = 16000 // whisper.cpp expects 16kHz; see SetTranscriptionSampleRate
+	channels         = 1     // the transcription WAV is always mono
 	bitDepth         = 16
 	bufferSize       = 1024
 	spectrumBands    = 32
+	spectrumMinFreq  = 80.0
+
+	// Default top of the spectrum; see SetSpectrumMaxFrequency
+	defaultSpectrumMaxFreq = 12000.0
+
+	// Default band magnitude shown at full scale; see SetSpectrumGain
+	defaultSpectrumReference = 800.0
+
+	// Default spectrum attack/release time constants; see SetSpectrumSmoothing
+	defaultSpectrumAttack  = 0.03
+	defaultSpectrumRelease = 0.25
+
+	// streamStopTimeout bounds how long we wait for a stuck stream to stop
+	streamStopTimeout = 3 * time.Second
 )
 
 type recordingState int
@@ -28,6 +42,8 @@ const (
 	stateIdle recordingState = iota
 	stateRecording
 	statePaused
+	stateStopping    // stream is being stopped and the WAV written
+	stateCalibrating // CaptureNoiseProfile is listening
 )
 
 func (s recordingState) String() string {
@@ -36,6 +52,10 @@ func (s recordingState) String() string {
 		return "recording"
 	case statePaused:
 		return "paused"
+	case stateStopping:
+		return "stopping"
+	case stateCalibrating:
+		return "calibrating"
 	default:
 		return "idle"
 	}
@@ -44,16 +64,124 @@ func (s recordingState) String() string {
 type AudioService struct {
 	mu          sync.Mutex
 	state       recordingState
-	stream      *portaudio.Stream
+	stream      audioStream
 	nativeSR    float64 // device's native sample rate
-	samples     []int16 // recorded at native sample rate
+	numChannels int     // channels in the current recording
+	samples     []int16 // recorded at native sample rate, interleaved if stereo
 	startTime   time.Time
 	elapsed     time.Duration
 	pauseStart  time.Time
 	totalPaused time.Duration
 
-	// Ring buffer for spectrum visualization (latest callback data)
+	// System audio mixed in with SelectSystemAudioDevice: the device, its
+	// source while recording, and what it recorded, mono with one sample
+	// per frame of samples
+	systemDevice  *InputDeviceInfo
+	system        *systemSource
+	systemSamples []int16
+
+	// Ring buffer for spectrum visualization (latest callback data). The
+	// callback replaces it rather than writing into it, so GetSpectrum can
+	// read a snapshot after releasing a.mu
 	specBuf []int16
+	// Smoothed band values returned by GetSpectrum, guarded by specMu so
+	// spectrum polling only takes a.mu for the snapshot
+	specMu       sync.Mutex
+	specSmoothed []float64
+	specUpdated  time.Time
+	specAttack   float64 // seconds
+	specRelease  float64 // seconds
+	specMaxFreq  float64 // Hz; 0 means defaultSpectrumMaxFreq
+	// Level mapping; see SetSpectrumGain
+	specReference float64 // 0 means defaultSpectrumReference
+	specCurve     string  // "" means log
+
+	// Peaks not yet sent as "audio:waveform-chunk", and how many were sent
+	wavePeaks []float64
+	waveIndex int
+
+	// sampleFormat was negotiated with the device; see GetSampleFormat
+	sampleFormat string
+
+	// Room noise from CaptureNoiseProfile, or nil
+	noiseProfile *noiseProfile
+
+	// Level meter; see level.go
+	levelPeak    float64 // linear, 0-1
+	levelRMS     float64
+	holdPeak     float64
+	holdSince    time.Time
+	peakHoldTime time.Duration
+	peakDecay    float64 // dB per second
+	// Per-source RMS with system audio, after their gains
+	levelMicRMS    float64
+	levelSystemRMS float64
+
+	// Device chosen via SelectInputDevice; nil means the host API's default
+	selectedDevice *InputDeviceInfo
+	// Backend chosen via SelectHostAPI; nil means the system default
+	selectedHost *portaudio.HostApiInfo
+
+	// lowPower skips spectrum bookkeeping while nobody is watching
+	lowPower bool
+
+	// Recording options; see SetRecordingProfile
+	recChannels int  // requested channel count; 0 means mono
+	archiveSR   int  // sample rate for exported audio; 0 means native
+	archiveBits int  // bit depth for exported audio; 0 means 16
+	noDither    bool // see SetDither
+	keepNative  bool // also save the recording at nativeSR; see SetKeepNativeRecording
+	highPass    bool
+	normalize   bool
+	// telephoneBand band-limits the transcription WAV; see SetTelephoneBandpass
+	telephoneBand bool
+	micGainDb     float64
+	systemGainDb  float64 // see SetSystemGain
+	autoBalance   bool    // see SetAutoBalance
+
+	// Speech tracking for the inactivity reminder
+	inactivityAfter    time.Duration
+	lastSpeech         time.Time
+	inactivityNotified bool
+
+	// Auto-stop limits and the pending countdown; see autostop.go
+	autoStopSilence      time.Duration
+	maxDuration          time.Duration
+	autoStopGrace        time.Duration
+	autoStopCancel       chan struct{} // non-nil while an auto-stop is pending
+	autoStopReason       string
+	maxDurationDismissed bool
+
+	// transcriptionSR is the sample rate of the WAV handed to whisper;
+	// 0 means outputSampleRate
+	transcriptionSR int
+	// resampleTaps selects the windowed-sinc resampler; 0 means linear
+	resampleTaps int
+
+	// Seconds dropped from the start and end of saved recordings
+	trimStart, trimEnd float64
+
+	// recordingID identifies the current or last recording; see registry.go
+	recordingID string
+	// recordingDone is closed when the current recording stops
+	recordingDone chan struct{}
+	// stopHandler runs in the background after StopRecording saves the audio
+	stopHandler func(RecordingInfo)
+
+	// streamStuck is set when a stream failed to stop, after which
+	// portaudio can't be terminated safely
+	streamStuck bool
+
+	// compressedTemp saves the transcription audio as FLAC; see SetCompressedTemp
+	compressedTemp bool
+
+	// Segment files written so far; see SetSegmentOnResume. segmentMu is
+	// taken before a.mu and held while a segment is saved, so segments are
+	// saved one at a time and in order
+	segmentOnResume bool
+	segments        []string
+	segmentFrames   int // frames taken from a.samples into segments
+	segmentMu       sync.Mutex
 }
 
 func (a *AudioService) ServiceName() string {
@@ -61,13 +189,127 @@ func (a *AudioService) ServiceName() string {
 }
 
 func (a *AudioService) ServiceStartup(_ context.Context, _ application.ServiceOptions) error {
+	a.mu.Lock()
+	a.specAttack = defaultSpectrumAttack
+	a.specRelease = defaultSpectrumRelease
+	a.peakHoldTime = defaultPeakHoldMs * time.Millisecond
+	a.peakDecay = defaultPeakDecayDbPerSec
+	a.mu.Unlock()
 	return portaudio.Initialize()
 }
 
+// ServiceShutdown stops any active recording and flushes the captured audio
+// to a recovery WAV so quitting mid-meeting doesn't lose it.
 func (a *AudioService) ServiceShutdown() error {
+	if err := a.ReleaseAudio(); err != nil {
+		return fmt.Errorf("failed to stop stream on shutdown: %w", err)
+	}
+
+	a.mu.Lock()
+	stuck := a.streamStuck
+	a.mu.Unlock()
+	if stuck {
+		// A stuck stream would also block Terminate, so leave cleanup to process exit
+		return fmt.Errorf("audio stream did not stop; skipping portaudio shutdown")
+	}
 	return portaudio.Terminate()
 }
 
+// ReleaseAudio stops any active recording, saves what was captured to a
+// recovery WAV and closes the stream so the microphone is released, e.g.
+// when the window is closed. Safe to call repeatedly or when idle.
+func (a *AudioService) ReleaseAudio() error {
+	// Wait for a segment being saved, so its samples aren't missed
+	a.segmentMu.Lock()
+	defer a.segmentMu.Unlock()
+
+	a.mu.Lock()
+	stream, system := a.stream, a.system
+	active := a.state == stateRecording || a.state == statePaused
+	if active {
+		// Stop accumulating before releasing the lock so the callback
+		// can't block the stream from stopping. The stopping state also
+		// keeps devices from being refreshed under the closing stream.
+		a.state = stateStopping
+		a.stream, a.system = nil, nil
+		a.endRecording()
+	}
+	id := a.recordingID
+	a.mu.Unlock()
+
+	if !active {
+		return nil
+	}
+
+	stopErr := stopStreams(stream, system)
+	// Taken once the stream has stopped, so it has every callback's samples
+	a.mu.Lock()
+	snap := a.snapshotAudio()
+	// From the audio captured, since the recovery copy isn't trimmed;
+	// segments already saved count too
+	var duration float64
+	if a.nativeSR > 0 {
+		duration = float64(a.segmentFrames+len(a.samples)/max(a.numChannels, 1)) / a.nativeSR
+	}
+	a.state = stateIdle
+	if stopErr != nil {
+		a.streamStuck = true
+	}
+	a.mu.Unlock()
+	if path, hash, err := writeRecoveryWAV(snap); err != nil {
+		log.Printf("failed to save recording: %v", err)
+	} else if path != "" {
+		recordings.update(id, func(r *RecordingInfo) {
+			r.Duration = duration
+			if len(r.SegmentPaths) > 0 {
+				r.SegmentPaths = append(r.SegmentPaths, path)
+				return
+			}
+			r.WavPath = path
+			r.AudioHash = hash
+		})
+		log.Printf("saved in-progress recording to %s", path)
+	}
+	return stopErr
+}
+
+// audioStream is the part of a portaudio stream the service drives.
+type audioStream interface {
+	Start() error
+	Stop() error
+	Close() error
+}
+
+// stopStream stops and closes s, giving up after timeout so a stuck device
+// can't hang the caller.
+func stopStream(s audioStream, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		err := s.Stop()
+		s.Close()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// stopStreams stops the microphone stream and, if there is one, the system
+// audio stream, returning the first error.
+func stopStreams(stream audioStream, system *systemSource) error {
+	err := stopStream(stream, streamStopTimeout)
+	if system != nil {
+		if sysErr := stopStream(system.stream, streamStopTimeout); err == nil && sysErr != nil {
+			err = fmt.Errorf("system audio: %w", sysErr)
+		}
+	}
+	return err
+}
+
 func (a *AudioService) StartRecording() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -75,58 +317,138 @@ func (a *AudioService) StartRecording() error {
 	if a.state != stateIdle {
 		return fmt.Errorf("cannot start recording: current state is %s", a.state)
 	}
+	// Without permission macOS delivers silence instead of an error
+	if status, _ := micPermissionStatus(); status == micPermissionDenied || status == micPermissionRestricted {
+		return fmt.Errorf("cannot start recording: microphone access is %s; allow it in System Settings > Privacy & Security > Microphone", status)
+	}
 
 	// Detect native sample rate
-	host, err := portaudio.DefaultHostApi()
+	dev, err := a.resolveInputDevice()
 	if err != nil {
-		return fmt.Errorf("failed to get default host API: %w", err)
-	}
-	dev := host.DefaultInputDevice
-	if dev == nil {
-		return fmt.Errorf("no default input device found")
+		return err
 	}
 	a.nativeSR = dev.DefaultSampleRate
+	a.numChannels = recordingChannels(dev, a.recChannels)
 
-	a.samples = nil
+	a.samples, a.systemSamples = nil, nil
+	a.segments, a.segmentFrames = nil, 0
 	a.totalPaused = 0
 	a.specBuf = nil
-
-	stream, err := portaudio.OpenDefaultStream(channels, 0, a.nativeSR, bufferSize, func(in []int16) {
-		a.mu.Lock()
-		defer a.mu.Unlock()
-		// Always update spectrum buffer for visualization
-		a.specBuf = make([]int16, len(in))
-		copy(a.specBuf, in)
-		if a.state == stateRecording {
-			a.samples = append(a.samples, in...)
-		}
+	a.specMu.Lock()
+	a.specSmoothed = nil
+	a.specMu.Unlock()
+	a.resetLevel()
+	a.wavePeaks, a.waveIndex = nil, 0
+
+	numChannels := a.numChannels
+	stream, format, err := openInputStream(dev, numChannels, !a.noDither, func(in []int16) {
+		a.handleInput(in, numChannels)
 	})
 	if err != nil {
 		return fmt.Errorf("failed to open audio stream: %w", err)
 	}
 
+	var system *systemSource
+	if a.systemDevice != nil {
+		if system, err = a.openSystemSource(); err != nil {
+			stream.Close()
+			return err
+		}
+	}
+
 	if err := stream.Start(); err != nil {
 		stream.Close()
+		if system != nil {
+			system.stream.Close()
+		}
 		return fmt.Errorf("failed to start audio stream: %w", err)
 	}
 
-	a.stream = stream
+	a.stream, a.system = stream, system
+	a.sampleFormat = format
 	a.state = stateRecording
 	a.startTime = time.Now()
+	a.lastSpeech = a.startTime
+	a.inactivityNotified = false
+	a.autoStopCancel = nil
+	a.maxDurationDismissed = false
+	a.recordingDone = make(chan struct{})
+	a.recordingID = newRecordingID(a.startTime)
+	recordings.add(RecordingInfo{ID: a.recordingID, StartedAt: a.startTime})
+
+	// Written off the lock; it's file I/O
+	go rememberInputDevice(dev.Name)
+
+	if msg := sampleRateProblem(a.nativeSR, a.transcriptionRate()); msg != "" {
+		log.Print(msg)
+		// Emit after the lock is released so listeners can call back in
+		go application.Get().Event.Emit("audio:sample-rate-warning", SampleRateWarning{
+			RecordingID: a.recordingID,
+			DeviceName:  dev.Name,
+			SampleRate:  a.nativeSR,
+			Message:     msg,
+		})
+	}
 
 	return nil
 }
 
-func (a *AudioService) PauseRecording() error {
+// handleInput is the stream callback: it feeds the meters and spectrum and,
+// while recording, keeps the samples.
+func (a *AudioService) handleInput(in []int16, numChannels int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	frames := len(in) / numChannels
+	// With system audio, the meters and speech detection follow the mix
+	mixed := in
+	var system []int16
+	if a.system != nil {
+		// Taken even while paused, so it stays in step with the microphone
+		system = a.system.pop(frames)
+		mixed = a.trackSourceLevels(in, numChannels, system)
+	}
+	a.trackLevel(mixed)
+	// Update spectrum buffer for visualization unless in low-power mode
+	if !a.lowPower {
+		if numChannels > 1 {
+			a.specBuf = mixToMono(in, numChannels)
+		} else {
+			a.specBuf = make([]int16, len(in))
+			copy(a.specBuf, in)
+		}
+	}
+	if a.state == stateRecording {
+		a.samples = append(a.samples, in...)
+		if system != nil {
+			a.systemSamples = append(a.systemSamples, system...)
+		}
+		a.trackWaveform(frames)
+		a.trackActivity(mixed)
+		a.checkAutoStop()
+	}
+}
 
+func (a *AudioService) PauseRecording() error {
+	a.segmentMu.Lock()
+	defer a.segmentMu.Unlock()
+
+	a.mu.Lock()
 	if a.state != stateRecording {
+		a.mu.Unlock()
 		return fmt.Errorf("cannot pause: current state is %s", a.state)
 	}
 
 	a.state = statePaused
 	a.pauseStart = time.Now()
+	var seg *pendingSegment
+	if a.segmentOnResume {
+		seg = a.takeSegment()
+	}
+	a.mu.Unlock()
+
+	if seg != nil {
+		a.savePausedSegment(seg)
+	}
 	return nil
 }
 
@@ -140,15 +462,42 @@ func (a *AudioService) ResumeRecording() error {
 
 	a.totalPaused += time.Since(a.pauseStart)
 	a.state = stateRecording
+	// Silence before the pause doesn't count toward the inactivity reminder
+	a.lastSpeech = time.Now()
 	return nil
 }
 
-func (a *AudioService) StopRecording() (string, error) {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+// AudioWarning is emitted as "audio:warning" for non-fatal problems the
+// user should know about.
+type AudioWarning struct {
+	RecordingID string `json:"recordingId"`
+	Message     string `json:"message"`
+}
 
-	if a.state == stateIdle {
-		return "", fmt.Errorf("not recording")
+// StopRecording ends the recording and writes the audio for transcription.
+// The returned info has the recording's ID and WavPath, or SegmentPaths
+// with SetSegmentOnResume.
+func (a *AudioService) StopRecording() (RecordingInfo, error) {
+	return a.stopRecording(nil)
+}
+
+// stopRecording is StopRecording. check, if set, runs with a.mu held once
+// the recording is known to be active; an error from it leaves the
+// recording running and is returned.
+func (a *AudioService) stopRecording(check func() error) (RecordingInfo, error) {
+	a.segmentMu.Lock()
+	defer a.segmentMu.Unlock()
+
+	a.mu.Lock()
+	if a.state != stateRecording && a.state != statePaused {
+		a.mu.Unlock()
+		return RecordingInfo{}, fmt.Errorf("not recording")
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			a.mu.Unlock()
+			return RecordingInfo{}, err
+		}
 	}
 
 	if a.state == statePaused {
@@ -157,20 +506,91 @@ func (a *AudioService) StopRecording() (string, error) {
 
 	a.elapsed = time.Since(a.startTime) - a.totalPaused
 
-	if err := a.stream.Stop(); err != nil {
-		a.stream.Close()
-		a.state = stateIdle
-		return "", fmt.Errorf("failed to stop stream: %w", err)
+	// Stop capturing, then release the lock while the stream stops so the
+	// callback can't deadlock against us. The stopping state keeps a new
+	// recording from replacing the samples in the meantime.
+	stream, system := a.stream, a.system
+	a.stream, a.system = nil, nil
+	a.state = stateStopping
+	a.endRecording()
+	a.mu.Unlock()
+
+	stopErr := stopStreams(stream, system)
+
+	// The captured audio is already in memory, so saving it takes
+	// precedence over a stream error. It's encoded and written off the
+	// lock; the stopping state keeps the samples in place meanwhile
+	a.mu.Lock()
+	snap := a.snapshotAudio()
+	segmented := len(a.segments) > 0
+	var seg *pendingSegment
+	if segmented {
+		// The rest goes in a final segment
+		seg = a.takeSegment()
+	}
+	id := a.recordingID
+	elapsed := a.elapsed
+	a.mu.Unlock()
+
+	var wavPath, nativePath, hash string
+	var err error
+	switch {
+	case seg != nil:
+		err = a.saveSegment(seg)
+	case !segmented:
+		wavPath, nativePath, hash, err = writeWAV(snap)
 	}
-	a.stream.Close()
+
+	a.mu.Lock()
 	a.state = stateIdle
+	if stopErr != nil {
+		a.streamStuck = true
+	}
+	stopHandler := a.stopHandler
+	a.mu.Unlock()
 
-	wavPath, err := a.writeWAV()
 	if err != nil {
-		return "", fmt.Errorf("failed to write WAV: %w", err)
+		if stopErr != nil {
+			return RecordingInfo{}, fmt.Errorf("failed to stop stream: %v; failed to write WAV: %w", stopErr, err)
+		}
+		return RecordingInfo{}, fmt.Errorf("failed to write WAV: %w", err)
+	}
+	recordings.update(id, func(r *RecordingInfo) {
+		r.WavPath = wavPath
+		r.NativeWavPath = nativePath
+		r.AudioHash = hash
+		r.Duration = elapsed.Seconds()
+	})
+
+	if stopErr != nil {
+		msg := fmt.Sprintf("audio device failed to stop cleanly (%v); the recording was saved", stopErr)
+		log.Print(msg)
+		if app := application.Get(); app != nil {
+			app.Event.Emit("audio:warning", AudioWarning{RecordingID: id, Message: msg})
+		}
+	}
+
+	info, _ := recordings.get(id)
+	if stopHandler != nil {
+		go stopHandler(info)
 	}
+	return info, nil
+}
 
-	return wavPath, nil
+// onRecordingStopped registers fn to run after each successful StopRecording.
+func (a *AudioService) onRecordingStopped(fn func(RecordingInfo)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopHandler = fn
+}
+
+// endRecording notifies anything waiting on recordingDone.
+// Callers must hold a.mu.
+func (a *AudioService) endRecording() {
+	if a.recordingDone != nil {
+		close(a.recordingDone)
+		a.recordingDone = nil
+	}
 }
 
 func (a *AudioService) GetElapsedTime() float64 {
@@ -193,26 +613,89 @@ func (a *AudioService) GetRecordingState() string {
 	return a.state.String()
 }
 
+// SetLowPowerMode stops spectrum updates to save battery, e.g. while the
+// window is hidden. Recording itself is unaffected and keeps every sample.
+func (a *AudioService) SetLowPowerMode(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lowPower = enabled
+	if enabled {
+		a.specBuf = nil
+	}
+}
+
 // GetSpectrum returns frequency band magnitudes (0.0-1.0) for visualization.
-// Uses logarithmic frequency scaling focused on the voice range (80Hz-12kHz).
+// Uses logarithmic frequency scaling focused on the voice range (80Hz up to
+// 12kHz by default, or the device's Nyquist frequency if that's lower). With
+// a noise profile, the room's noise floor is subtracted from each band.
+// a.mu is only held to copy the settings and the latest buffer's slice
+// header; the transform runs outside it so it can't delay the audio
+// callback.
 func (a *AudioService) GetSpectrum() []float64 {
 	a.mu.Lock()
 	buf := a.specBuf
 	sr := a.nativeSR
+	maxFreq := a.spectrumCeiling()
+	noise := a.noiseProfile
+	reference := a.specReference
+	if reference == 0 {
+		reference = defaultSpectrumReference
+	}
+	logCurve := a.specCurve != spectrumCurveLinear
+	attack, release := a.specAttack, a.specRelease
 	a.mu.Unlock()
 
+	// In low-power mode specBuf is cleared, so this returns all zeros
 	result := make([]float64, spectrumBands)
 	if len(buf) == 0 || sr == 0 {
-		return result
+		return a.smoothSpectrum(result, attack, release)
+	}
+
+	bands := bandMagnitudes(buf, sr, maxFreq)
+	if noise != nil && noise.sampleRate == sr && noise.maxFreq == maxFreq {
+		for i := range bands {
+			bands[i] = max(bands[i]-noise.bands[i], 0)
+		}
+	}
+
+	for band, sum := range bands {
+		// Log scaling gives quiet speech more of the range
+		normalized := sum / reference
+		if logCurve && normalized > 0 {
+			normalized = math.Log10(normalized*9 + 1)
+		}
+		if normalized > 1.0 {
+			normalized = 1.0
+		}
+		result[band] = normalized
 	}
 
+	return a.smoothSpectrum(result, attack, release)
+}
+
+// spectrumCeiling returns the configured top of the spectrum.
+// Callers must hold a.mu.
+func (a *AudioService) spectrumCeiling() float64 {
+	if a.specMaxFreq == 0 {
+		return defaultSpectrumMaxFreq
+	}
+	return a.specMaxFreq
+}
+
+// bandMagnitudes returns the average DFT magnitude of buf in each of the
+// spectrumBands logarithmic bands from 80Hz to maxFreq. maxFreq is clamped
+// to Nyquist, since bands above it would always be empty; bands past the
+// last DFT bin are zero.
+func bandMagnitudes(buf []int16, sr, maxFreq float64) []float64 {
+	result := make([]float64, spectrumBands)
 	n := len(buf)
 	freqRes := sr / float64(n) // Hz per DFT bin
 
-	// Logarithmic band edges from 80Hz to 12kHz
-	const minFreq = 80.0
-	const maxFreq = 12000.0
-	logMin := math.Log2(minFreq)
+	maxFreq = min(maxFreq, sr/2)
+	if n == 0 || maxFreq <= spectrumMinFreq {
+		return result
+	}
+	logMin := math.Log2(spectrumMinFreq)
 	logMax := math.Log2(maxFreq)
 
 	// Compute DFT magnitudes for all needed bins (up to maxFreq)
@@ -241,6 +724,10 @@ func (a *AudioService) GetSpectrum() []float64 {
 		if kLow < 1 {
 			kLow = 1
 		}
+		if kLow > maxBin {
+			// Above the highest computed bin; leave it silent
+			continue
+		}
 		if kHigh > maxBin {
 			kHigh = maxBin
 		}
@@ -258,83 +745,210 @@ func (a *AudioService) GetSpectrum() []float64 {
 		if count > 0 {
 			sum /= float64(count)
 		}
+		result[band] = sum
+	}
+	return result
+}
 
-		// Normalize with log scaling for better dynamic range
-		// Apply gain boost for typical speech levels
-		normalized := sum / 800.0
-		if normalized > 0 {
-			normalized = (math.Log10(normalized*9+1) / math.Log10(10))
+// SetSpectrumSmoothing sets the attack and release time constants, in
+// seconds, applied to GetSpectrum so bars rise quickly and fall gently.
+// Zero for both returns the raw per-call values.
+func (a *AudioService) SetSpectrumSmoothing(attack, release float64) error {
+	if attack < 0 || release < 0 || attack > 10 || release > 10 {
+		return fmt.Errorf("spectrum attack and release must be between 0 and 10 seconds")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.specAttack = attack
+	a.specRelease = release
+	return nil
+}
+
+// SetSpectrumMaxFrequency sets the top of the visualizer's frequency range,
+// between 1kHz and 48kHz. Sources whose Nyquist frequency is lower, like
+// phone audio, use that instead so no bands are wasted.
+func (a *AudioService) SetSpectrumMaxFrequency(hz float64) error {
+	if hz < 1000 || hz > 48000 {
+		return fmt.Errorf("spectrum ceiling must be between 1000 and 48000 Hz, got %g", hz)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.specMaxFreq = hz
+	return nil
+}
+
+// Curves for SetSpectrumGain
+const (
+	spectrumCurveLog    = "log"
+	spectrumCurveLinear = "linear"
+)
+
+// SetSpectrumGain calibrates GetSpectrum for different mics and input
+// gains. reference is the band magnitude shown at full scale (800 by
+// default; lower it for quiet sources). curve is "log", the default, which
+// lifts quiet levels, or "linear".
+func (a *AudioService) SetSpectrumGain(reference float64, curve string) error {
+	if reference <= 0 || reference > int16FullScale {
+		return fmt.Errorf("spectrum reference must be between 0 and %d, got %g", int16FullScale, reference)
+	}
+	switch curve {
+	case spectrumCurveLog, spectrumCurveLinear:
+	default:
+		return fmt.Errorf("unknown spectrum curve %q (want log or linear)", curve)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.specReference = reference
+	a.specCurve = curve
+	return nil
+}
+
+// smoothSpectrum blends raw into the running band values using the time
+// since the previous call, so smoothing doesn't depend on the polling rate.
+// attack and release are the SetSpectrumSmoothing time constants.
+func (a *AudioService) smoothSpectrum(raw []float64, attack, release float64) []float64 {
+	a.specMu.Lock()
+	defer a.specMu.Unlock()
+
+	now := time.Now()
+	dt := now.Sub(a.specUpdated).Seconds()
+	a.specUpdated = now
+	if len(a.specSmoothed) != len(raw) {
+		a.specSmoothed = make([]float64, len(raw))
+	}
+
+	for i, v := range raw {
+		tau := release
+		if v > a.specSmoothed[i] {
+			tau = attack
 		}
-		if normalized > 1.0 {
-			normalized = 1.0
+		if tau <= 0 {
+			a.specSmoothed[i] = v
+			continue
 		}
-		result[band] = normalized
+		a.specSmoothed[i] += (v - a.specSmoothed[i]) * (1 - math.Exp(-dt/tau))
 	}
 
+	result := make([]float64, len(raw))
+	copy(result, a.specSmoothed)
 	return result
 }
 
-// downsample converts from nativeSR to outputSampleRate using simple linear interpolation.
-func (a *AudioService) downsample() []int16 {
-	if a.nativeSR == float64(outputSampleRate) {
-		return a.samples
+// transcriptionRate returns the sample rate of the transcription WAV.
+// Callers must hold a.mu.
+func (a *AudioService) transcriptionRate() int {
+	if a.transcriptionSR == 0 {
+		return outputSampleRate
 	}
+	return a.transcriptionSR
+}
 
-	ratio := a.nativeSR / float64(outputSampleRate)
-	outLen := int(float64(len(a.samples)) / ratio)
-	out := make([]int16, outLen)
+// audioSnapshot is a recording's samples along with the settings used to
+// save them, so the processing and file I/O can run without holding a.mu.
+type audioSnapshot struct {
+	samples     []int16 // at nativeSR, interleaved if stereo
+	system      []int16 // mono system audio, one sample per frame, or nil
+	nativeSR    float64
+	numChannels int
+	sr          int // transcription sample rate
+	taps        int // see SetResampleFilterTaps
+	keepNative  bool
+
+	trimStart, trimEnd float64
+	micGainDb          float64
+	systemGainDb       float64
+	autoBalance        bool
+	highPass           bool
+	telephoneBand      bool
+	normalize          bool
+	compressed         bool
+
+	recordingID string // for warnings
+}
 
-	for i := range out {
-		srcPos := float64(i) * ratio
-		idx := int(srcPos)
-		frac := srcPos - float64(idx)
+// snapshotAudio captures the recording and its save settings. The callback
+// only ever appends to a.samples, so the snapshot shares them rather than
+// copying; its capacity is capped so nothing appended to it can reach the
+// live buffer. Callers must hold a.mu.
+func (a *AudioService) snapshotAudio() audioSnapshot {
+	return audioSnapshot{
+		samples:       a.samples[:len(a.samples):len(a.samples)],
+		system:        a.systemSamples[:len(a.systemSamples):len(a.systemSamples)],
+		nativeSR:      a.nativeSR,
+		numChannels:   a.numChannels,
+		sr:            a.transcriptionRate(),
+		taps:          a.resampleTaps,
+		keepNative:    a.keepNative,
+		trimStart:     a.trimStart,
+		trimEnd:       a.trimEnd,
+		micGainDb:     a.micGainDb,
+		systemGainDb:  a.systemGainDb,
+		autoBalance:   a.autoBalance,
+		highPass:      a.highPass,
+		telephoneBand: a.telephoneBand,
+		normalize:     a.normalize,
+		compressed:    a.compressedTemp,
+		recordingID:   a.recordingID,
+	}
+}
 
-		if idx+1 < len(a.samples) {
-			out[i] = int16(float64(a.samples[idx])*(1-frac) + float64(a.samples[idx+1])*frac)
-		} else if idx < len(a.samples) {
-			out[i] = a.samples[idx]
-		}
+// writeWAV saves the recording for transcription and, with
+// SetKeepNativeRecording, a copy at the native rate. A failure to write the
+// copy is only logged, since the transcription audio is what matters.
+func writeWAV(s audioSnapshot) (path, nativePath, hash string, err error) {
+	tmpDir := os.TempDir()
+	filename := fmt.Sprintf("meeting_%s", time.Now().Format("20060102_150405"))
+	base := filepath.Join(tmpDir, filename)
+	path, hash, err = writeTempAudio(s, base, true)
+	if err != nil || !s.keepNative {
+		return path, "", hash, err
 	}
 
-	return out
+	nativePath = base + "_native.wav"
+	if err := writePCMWAV(nativePath, s.samples, int(s.nativeSR), s.numChannels); err != nil {
+		log.Printf("failed to save native-rate recording: %v", err)
+		os.Remove(nativePath)
+		return path, "", hash, nil
+	}
+	return path, nativePath, hash, nil
 }
 
-func (a *AudioService) writeWAV() (string, error) {
-	tmpDir := os.TempDir()
-	filename := fmt.Sprintf("meeting_%s.wav", time.Now().Format("20060102_150405"))
-	wavPath := filepath.Join(tmpDir, filename)
+// writeRecoveryWAV saves the captured samples after an interrupted recording.
+// Returns "" when there is nothing to save.
+func writeRecoveryWAV(s audioSnapshot) (path, hash string, err error) {
+	if len(s.samples) == 0 {
+		return "", "", nil
+	}
 
-	// Downsample to 16kHz for whisper.cpp
-	samples := a.downsample()
+	filename := fmt.Sprintf("meeting_recovery_%s", time.Now().Format("20060102_150405"))
+	return writeTempAudio(s, filepath.Join(os.TempDir(), filename), false)
+}
 
-	f, err := os.Create(wavPath)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-
-	dataSize := uint32(len(samples) * 2) // 16-bit = 2 bytes per sample
-	fileSize := 36 + dataSize
-
-	// RIFF header
-	f.Write([]byte("RIFF"))
-	binary.Write(f, binary.LittleEndian, fileSize)
-	f.Write([]byte("WAVE"))
-
-	// fmt sub-chunk
-	f.Write([]byte("fmt "))
-	binary.Write(f, binary.LittleEndian, uint32(16))                                      // sub-chunk size
-	binary.Write(f, binary.LittleEndian, uint16(1))                                        // PCM format
-	binary.Write(f, binary.LittleEndian, uint16(channels))                                 // channels
-	binary.Write(f, binary.LittleEndian, uint32(outputSampleRate))                         // sample rate
-	binary.Write(f, binary.LittleEndian, uint32(outputSampleRate*channels*bitDepth/8))     // byte rate
-	binary.Write(f, binary.LittleEndian, uint16(channels*bitDepth/8))                      // block align
-	binary.Write(f, binary.LittleEndian, uint16(bitDepth))                                 // bits per sample
-
-	// data sub-chunk
-	f.Write([]byte("data"))
-	binary.Write(f, binary.LittleEndian, dataSize)
-	binary.Write(f, binary.LittleEndian, samples)
-
-	return wavPath, nil
+// transcriptionSamples returns the recording as processed for whisper, at
+// s.sr. trim drops the SetTrimEdges margins first.
+func (s audioSnapshot) transcriptionSamples(trim bool) []int16 {
+	// Downsample to 16kHz (by default) for whisper.cpp
+	mono := mixToMono(s.samples, s.numChannels)
+	micGainDb := s.micGainDb
+	if s.system != nil {
+		mono = mixSystemAudio(mono, s.system, s.nativeSR, s.micGainDb, s.systemGainDb, s.autoBalance)
+		micGainDb = 0 // applied in the mix
+	}
+	samples := resampleTaps(mono, s.nativeSR, float64(s.sr), s.taps, nil)
+	if trim {
+		samples = s.trimEdges(samples)
+	}
+	if micGainDb != 0 {
+		samples = applyGain(samples, micGainDb)
+	}
+	if s.highPass {
+		samples = highPassFilter(samples, float64(s.sr), highPassCutoff)
+	}
+	if s.telephoneBand {
+		samples = bandPassFilter(samples, float64(s.sr), telephoneLow, telephoneHigh)
+	}
+	if s.normalize {
+		samples = normalizePeak(samples)
+	}
+	return samples
 }
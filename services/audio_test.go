@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetSpectrumDoesNotStallCallback(t *testing.T) {
+	const (
+		sampleRate = 48000
+		frames     = 256 // about 5ms per buffer, a typical low-latency size
+		buffers    = 100
+	)
+	period := time.Duration(frames) * time.Second / sampleRate
+
+	// Idle, so the callback only feeds the meters and spectrum
+	a := &AudioService{nativeSR: sampleRate}
+	in := make([]int16, frames)
+	for i := range in {
+		in[i] = int16(8000 * math.Sin(2*math.Pi*1000*float64(i)/sampleRate))
+	}
+
+	var stop atomic.Bool
+	var polls atomic.Int64
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				a.GetSpectrum()
+				polls.Add(1)
+			}
+		}()
+	}
+
+	// A callback that takes longer than its buffer means the device drops
+	// input
+	dropped := 0
+	for range buffers {
+		began := time.Now()
+		a.handleInput(in, 1)
+		if time.Since(began) > period {
+			dropped++
+		}
+		time.Sleep(period)
+	}
+	stop.Store(true)
+	wg.Wait()
+
+	if dropped > 0 {
+		t.Errorf("%d of %d callbacks overran their buffer while the spectrum was polled %d times", dropped, buffers, polls.Load())
+	}
+	if polls.Load() == 0 {
+		t.Fatal("GetSpectrum was never polled")
+	}
+	var peak float64
+	for _, v := range a.GetSpectrum() {
+		peak = max(peak, v)
+	}
+	if peak == 0 {
+		t.Error("spectrum is silent for a 1kHz tone")
+	}
+}
+
+// fakeStream stands in for a portaudio stream whose Stop fails.
+type fakeStream struct {
+	stopErr error
+	closed  bool
+}
+
+func (s *fakeStream) Start() error { return nil }
+func (s *fakeStream) Stop() error  { return s.stopErr }
+func (s *fakeStream) Close() error { s.closed = true; return nil }
+
+func TestStopRecordingSavesAudioWhenStreamStopFails(t *testing.T) {
+	useTempHome(t)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	samples := make([]int16, 48000)
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/48000))
+	}
+	stream := &fakeStream{stopErr: errors.New("device unplugged")}
+	start := time.Now().Add(-time.Second)
+	id := newRecordingID(start)
+	recordings.add(RecordingInfo{ID: id, StartedAt: start})
+	a := &AudioService{
+		state:       stateRecording,
+		stream:      stream,
+		nativeSR:    48000,
+		numChannels: 1,
+		samples:     samples,
+		startTime:   start,
+		recordingID: id,
+	}
+
+	info, err := a.StopRecording()
+	if err != nil {
+		t.Fatalf("StopRecording() = %v, want the recording saved despite the stream error", err)
+	}
+	if !stream.closed {
+		t.Error("stream was not closed")
+	}
+	if got := a.GetRecordingState(); got != "idle" {
+		t.Errorf("state after StopRecording = %s, want idle", got)
+	}
+	if info.ID != id || info.WavPath == "" {
+		t.Fatalf("StopRecording() = %+v, want the saved WAV for %s", info, id)
+	}
+	saved, wav, err := readWAV(info.WavPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wav.sampleRate != outputSampleRate {
+		t.Errorf("saved at %dHz, want %dHz", wav.sampleRate, outputSampleRate)
+	}
+	if want := len(samples) * outputSampleRate / 48000; len(saved) != want {
+		t.Errorf("saved %d samples, want %d", len(saved), want)
+	}
+}
+
+func TestReleaseAudioRecordsDuration(t *testing.T) {
+	useTempHome(t)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	start := time.Now().Add(-time.Minute)
+	id := newRecordingID(start)
+	recordings.add(RecordingInfo{ID: id, StartedAt: start})
+	a := &AudioService{
+		state:       stateRecording,
+		stream:      &fakeStream{},
+		nativeSR:    48000,
+		numChannels: 2,
+		samples:     make([]int16, 2*48000*3/2), // 1.5s of stereo
+		startTime:   start,
+		recordingID: id,
+	}
+
+	if err := a.ReleaseAudio(); err != nil {
+		t.Fatal(err)
+	}
+	info, ok := recordings.get(id)
+	if !ok || info.WavPath == "" {
+		t.Fatalf("recording %s = %+v, want the recovery WAV registered", id, info)
+	}
+	if info.Duration != 1.5 {
+		t.Errorf("Duration = %v, want 1.5", info.Duration)
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"log"
+	"os"
+	"slices"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// AudioDeleted is emitted as "transcribe:audio-deleted" when
+// SetDeleteWAVAfterTranscription removes a recording's audio.
+type AudioDeleted struct {
+	RecordingID string   `json:"recordingId,omitempty"`
+	Paths       []string `json:"paths"`
+}
+
+// SetDeleteWAVAfterTranscription removes a recording's audio once
+// TranscribeToFile has written its transcript: the temp recording, the
+// native-rate copy and the copy saved next to the markdown. Nothing is
+// deleted when transcription fails. The saved copy is kept while
+// SetAudioLinkedTimestamps is on, since the markdown links to it, and audio
+// the app didn't record itself, like files given to TranscribeBatch, is
+// never deleted.
+func (t *TranscribeService) SetDeleteWAVAfterTranscription(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.deleteAudio = enabled
+}
+
+// deleteTranscribedAudio removes the audio of a successfully transcribed
+// recording according to SetDeleteWAVAfterTranscription, returning the
+// files deleted. For a recording split with SetSegmentOnResume only the
+// segment at wavPath is removed, since the others may not be transcribed yet.
+func (c transcribeConfig) deleteTranscribedAudio(recordingID, wavPath, savedAudioPath string) []string {
+	if !c.deleteAudio {
+		return nil
+	}
+	var paths []string
+	segment := false
+	if info, ok := recordings.get(recordingID); ok {
+		if slices.Contains(info.SegmentPaths, wavPath) {
+			segment = true
+			paths = append(paths, wavPath)
+		} else {
+			paths = append(paths, info.WavPath, info.NativeWavPath)
+		}
+	}
+	if !c.audioLinks {
+		paths = append(paths, savedAudioPath)
+	}
+
+	// Files already missing aren't reported as deleted, but are cleared
+	// from the registry along with the deleted ones
+	var deleted []string
+	failed := make(map[string]bool)
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		err := os.Remove(p)
+		switch {
+		case err == nil:
+			deleted = append(deleted, p)
+		case !os.IsNotExist(err):
+			log.Printf("failed to delete transcribed audio: %v", err)
+			failed[p] = true
+		}
+	}
+
+	recordings.update(recordingID, func(r *RecordingInfo) {
+		clearPath := func(p *string) {
+			if !failed[*p] {
+				*p = ""
+			}
+		}
+		if segment {
+			if !failed[wavPath] {
+				r.SegmentPaths = slices.DeleteFunc(r.SegmentPaths, func(p string) bool { return p == wavPath })
+			}
+		} else {
+			clearPath(&r.WavPath)
+			clearPath(&r.NativeWavPath)
+		}
+		if !c.audioLinks {
+			clearPath(&r.SavedAudioPath)
+		}
+	})
+	if len(deleted) == 0 {
+		return nil
+	}
+	if app := application.Get(); app != nil {
+		app.Event.Emit("transcribe:audio-deleted", AudioDeleted{RecordingID: recordingID, Paths: deleted})
+	}
+	return deleted
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useTempHome points the home and config directories at a temporary
+// directory, so registry updates and saves don't touch the user's settings.
+func useTempHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return home
+}
+
+func TestDeleteTranscribedAudioReportsOnlyRemovedFiles(t *testing.T) {
+	useTempHome(t)
+
+	dir := t.TempDir()
+	wavPath := filepath.Join(dir, "meeting.wav")
+	savedPath := filepath.Join(dir, "saved.wav")
+	missingPath := filepath.Join(dir, "native.wav") // never written
+	for _, p := range []string{wavPath, savedPath} {
+		if err := os.WriteFile(p, []byte("audio"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id := newRecordingID(time.Now())
+	recordings.add(RecordingInfo{ID: id, WavPath: wavPath, NativeWavPath: missingPath, SavedAudioPath: savedPath})
+
+	deleted := transcribeConfig{deleteAudio: true}.deleteTranscribedAudio(id, wavPath, savedPath)
+
+	if want := []string{wavPath, savedPath}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleteTranscribedAudio() = %v, want %v", deleted, want)
+	}
+	for _, p := range []string{wavPath, savedPath} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", filepath.Base(p))
+		}
+	}
+	info, _ := recordings.get(id)
+	if info.WavPath != "" || info.NativeWavPath != "" || info.SavedAudioPath != "" {
+		t.Errorf("registry still lists audio: %+v", info)
+	}
+}
+
+func TestDeleteTranscribedAudioNothingToDelete(t *testing.T) {
+	useTempHome(t)
+
+	dir := t.TempDir()
+	id := newRecordingID(time.Now())
+	recordings.add(RecordingInfo{ID: id, WavPath: filepath.Join(dir, "gone.wav")})
+
+	if deleted := (transcribeConfig{deleteAudio: true}).deleteTranscribedAudio(id, filepath.Join(dir, "gone.wav"), ""); deleted != nil {
+		t.Errorf("deleteTranscribedAudio() = %v for files that were already gone", deleted)
+	}
+	if info, _ := recordings.get(id); info.WavPath != "" {
+		t.Errorf("registry still lists %s", info.WavPath)
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// Reasons reported in auto-stop events
+const (
+	autoStopReasonSilence     = "silence"
+	autoStopReasonMaxDuration = "max-duration"
+)
+
+// autoStopTick is how often "audio:auto-stop-pending" updates the countdown.
+const autoStopTick = time.Second
+
+// AutoStopEvent is emitted as "audio:auto-stop-pending" once a second while
+// an auto-stop counts down, then as "audio:auto-stopped" or
+// "audio:auto-stop-cancelled".
+type AutoStopEvent struct {
+	RecordingID string  `json:"recordingId"`
+	Reason      string  `json:"reason"`    // "silence" or "max-duration"
+	Remaining   float64 `json:"remaining"` // seconds until the recording stops
+}
+
+// SetAutoStopOnSilence stops the recording once no speech has been heard for
+// the given number of seconds. Zero disables it.
+func (a *AudioService) SetAutoStopOnSilence(seconds float64) error {
+	if seconds < 0 {
+		return fmt.Errorf("silence auto-stop must not be negative")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.autoStopSilence = time.Duration(seconds * float64(time.Second))
+	return nil
+}
+
+// SetMaxDuration stops the recording once it has run for the given number
+// of seconds, not counting pauses. Zero disables it.
+func (a *AudioService) SetMaxDuration(seconds float64) error {
+	if seconds < 0 {
+		return fmt.Errorf("max duration must not be negative")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.maxDuration = time.Duration(seconds * float64(time.Second))
+	return nil
+}
+
+// SetAutoStopGrace delays auto-stops by the given number of seconds, during
+// which "audio:auto-stop-pending" counts down and CancelAutoStop keeps the
+// recording going. Zero stops immediately.
+func (a *AudioService) SetAutoStopGrace(seconds float64) error {
+	if seconds < 0 {
+		return fmt.Errorf("auto-stop grace must not be negative")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.autoStopGrace = time.Duration(seconds * float64(time.Second))
+	return nil
+}
+
+// CancelAutoStop dismisses a pending auto-stop. A cancelled silence stop
+// starts counting silence afresh; a cancelled max-duration stop doesn't
+// fire again for this recording.
+func (a *AudioService) CancelAutoStop() error {
+	a.mu.Lock()
+	if a.autoStopCancel == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("no auto-stop is pending")
+	}
+	event := a.cancelAutoStop()
+	a.mu.Unlock()
+
+	application.Get().Event.Emit("audio:auto-stop-cancelled", event)
+	return nil
+}
+
+// cancelAutoStop ends the pending countdown, returning the event to emit
+// once a.mu is released. Callers must hold a.mu.
+func (a *AudioService) cancelAutoStop() AutoStopEvent {
+	close(a.autoStopCancel)
+	a.autoStopCancel = nil
+	if a.autoStopReason == autoStopReasonSilence {
+		a.lastSpeech = time.Now()
+	} else {
+		a.maxDurationDismissed = true
+	}
+	return AutoStopEvent{RecordingID: a.recordingID, Reason: a.autoStopReason}
+}
+
+// checkAutoStop starts the auto-stop countdown when the silence or duration
+// limit is reached. Callers must hold a.mu while recording.
+func (a *AudioService) checkAutoStop() {
+	if a.autoStopCancel != nil {
+		return
+	}
+
+	now := time.Now()
+	var reason string
+	switch {
+	case a.maxDuration > 0 && !a.maxDurationDismissed && now.Sub(a.startTime)-a.totalPaused >= a.maxDuration:
+		reason = autoStopReasonMaxDuration
+	case a.autoStopSilence > 0 && now.Sub(a.lastSpeech) >= a.autoStopSilence:
+		reason = autoStopReasonSilence
+	default:
+		return
+	}
+
+	cancel := make(chan struct{})
+	a.autoStopCancel = cancel
+	a.autoStopReason = reason
+	go a.runAutoStop(a.recordingID, reason, a.autoStopGrace, cancel, a.recordingDone)
+}
+
+// errAutoStopCancelled means the countdown was cancelled just before the
+// recording would have stopped.
+var errAutoStopCancelled = errors.New("auto-stop cancelled")
+
+// runAutoStop counts down the grace period and then stops the recording,
+// unless it's cancelled, speech resumes or the recording is stopped by hand
+// first.
+func (a *AudioService) runAutoStop(id, reason string, grace time.Duration, cancel chan struct{}, done <-chan struct{}) {
+	deadline := time.Now().Add(grace)
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	ticker := time.NewTicker(autoStopTick)
+	defer ticker.Stop()
+
+countdown:
+	for {
+		remaining := max(time.Until(deadline), 0)
+		application.Get().Event.Emit("audio:auto-stop-pending", AutoStopEvent{RecordingID: id, Reason: reason, Remaining: remaining.Seconds()})
+		select {
+		case <-cancel:
+			return
+		case <-done:
+			return
+		case <-timer.C:
+			break countdown
+		case <-ticker.C:
+		}
+	}
+
+	// Checked under the same lock as the stop, so a cancel, speech or a
+	// new recording arriving meanwhile can't be overridden
+	_, err := a.stopRecording(func() error {
+		if a.autoStopCancel != cancel || a.recordingID != id {
+			return errAutoStopCancelled
+		}
+		a.autoStopCancel = nil
+		return nil
+	})
+	if errors.Is(err, errAutoStopCancelled) {
+		return
+	}
+	if err != nil {
+		log.Printf("auto-stop failed: %v", err)
+		return
+	}
+	application.Get().Event.Emit("audio:auto-stopped", AutoStopEvent{RecordingID: id, Reason: reason})
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStopRecordingCheckKeepsRecording(t *testing.T) {
+	stream := &fakeStream{}
+	a := &AudioService{state: stateRecording, stream: stream, recordingID: "rec"}
+
+	_, err := a.stopRecording(func() error { return errAutoStopCancelled })
+	if !errors.Is(err, errAutoStopCancelled) {
+		t.Fatalf("stopRecording() = %v, want %v", err, errAutoStopCancelled)
+	}
+	if got := a.GetRecordingState(); got != "recording" {
+		t.Errorf("state = %s, want recording", got)
+	}
+	if stream.closed {
+		t.Error("stream was closed")
+	}
+}
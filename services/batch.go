@@ -0,0 +1,200 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// Batch file states
+const (
+	batchPending = "pending"
+	batchDone    = "done"
+	batchFailed  = "failed"
+)
+
+// BatchResult is the state of one file in a batch. At detailed progress
+// verbosity it's also emitted as "transcribe:batch" whenever a file finishes.
+type BatchResult struct {
+	Path         string `json:"path"`
+	Status       string `json:"status"` // "pending", "done" or "failed"
+	MarkdownPath string `json:"markdownPath,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// batch is the current or last batch. Its state is mirrored to a file in
+// the config directory so an interrupted batch can be resumed.
+var batch struct {
+	mu      sync.Mutex
+	running bool
+	files   []BatchResult
+}
+
+// ErrNoBatch is returned by ResumeBatch when there is nothing to resume.
+var ErrNoBatch = errors.New("no unfinished batch to resume")
+
+func batchStatePath() (string, error) {
+	path, err := settingsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "batch.json"), nil
+}
+
+// TranscribeBatch transcribes each file to markdown in turn, saving progress
+// after every file so the batch survives a crash or quit; see ResumeBatch.
+// A failed file doesn't stop the batch.
+func (t *TranscribeService) TranscribeBatch(paths []string) ([]BatchResult, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files to transcribe")
+	}
+	files := make([]BatchResult, len(paths))
+	for i, p := range paths {
+		files[i] = BatchResult{Path: p, Status: batchPending}
+	}
+	return t.runBatch(files)
+}
+
+// ResumeBatch continues a batch that was interrupted, skipping files that
+// are already done and retrying ones that failed.
+func (t *TranscribeService) ResumeBatch() error {
+	files, err := readBatchState()
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(files, func(f BatchResult) bool { return f.Status != batchDone }) {
+		return ErrNoBatch
+	}
+	_, err = t.runBatch(files)
+	return err
+}
+
+// GetBatchStatus returns the files of the current or last batch, including
+// an unfinished one left from a previous launch.
+func (t *TranscribeService) GetBatchStatus() []BatchResult {
+	batch.mu.Lock()
+	files := slices.Clone(batch.files)
+	batch.mu.Unlock()
+	if files != nil {
+		return files
+	}
+	saved, err := readBatchState()
+	if err != nil {
+		return []BatchResult{}
+	}
+	return saved
+}
+
+func (t *TranscribeService) runBatch(files []BatchResult) ([]BatchResult, error) {
+	batch.mu.Lock()
+	if batch.running {
+		batch.mu.Unlock()
+		return nil, fmt.Errorf("a batch is already running")
+	}
+	batch.running = true
+	batch.files = files
+	batch.mu.Unlock()
+	defer func() {
+		batch.mu.Lock()
+		batch.running = false
+		batch.mu.Unlock()
+	}()
+
+	if err := writeBatchState(files); err != nil {
+		return nil, err
+	}
+
+	cfg := t.config()
+	progress := cfg.newJobReporter("batch", "", len(files))
+	defer progress.done()
+	for i := range files {
+		if files[i].Status == batchDone {
+			continue
+		}
+
+		mdPath, err := t.TranscribeToFile(files[i].Path)
+		if err != nil && t.runContext().Err() != nil {
+			// Shutting down; leave the file pending for ResumeBatch
+			return nil, fmt.Errorf("batch interrupted: %w", err)
+		}
+
+		batch.mu.Lock()
+		if err != nil {
+			files[i] = BatchResult{Path: files[i].Path, Status: batchFailed, Error: err.Error()}
+		} else {
+			files[i] = BatchResult{Path: files[i].Path, Status: batchDone, MarkdownPath: mdPath}
+		}
+		result := files[i]
+		batch.mu.Unlock()
+
+		if err := writeBatchState(files); err != nil {
+			log.Printf("failed to save batch state: %v", err)
+		}
+		if cfg.detailedProgress() {
+			application.Get().Event.Emit("transcribe:batch", result)
+		}
+		progress.finished(i, result.Path)
+	}
+
+	if !slices.ContainsFunc(files, func(f BatchResult) bool { return f.Status != batchDone }) {
+		if path, err := batchStatePath(); err == nil {
+			os.Remove(path)
+		}
+	}
+	return slices.Clone(files), nil
+}
+
+func readBatchState() ([]BatchResult, error) {
+	path, err := batchStatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoBatch
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch state: %w", err)
+	}
+	var files []BatchResult
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse batch state: %w", err)
+	}
+	return files, nil
+}
+
+// writeBatchState saves files the same way writeSettings does, via a temp
+// file, so a crash mid-write can't lose the queue.
+func writeBatchState(files []BatchResult) error {
+	path, err := batchStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
+	}
+
+	batch.mu.Lock()
+	data, err := json.MarshalIndent(files, "", "  ")
+	batch.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode batch state: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save batch state: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save batch state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+const (
+	minBenchmarkSeconds = 1
+	maxBenchmarkSeconds = 120
+)
+
+// ModelBenchmark is the measured speed of one installed model.
+type ModelBenchmark struct {
+	Model string `json:"model"`
+	// RealTimeFactor is processing time divided by audio length, including
+	// model loading; below 1 is faster than real time
+	RealTimeFactor float64 `json:"realTimeFactor"`
+	Seconds        float64 `json:"seconds"`
+	PeakMemoryMB   float64 `json:"peakMemoryMB"` // 0 if the platform doesn't report it
+	Error          string  `json:"error,omitempty"`
+}
+
+// BenchmarkProgress is emitted as "transcribe:benchmark" before and after
+// each model is run.
+type BenchmarkProgress struct {
+	Model  string          `json:"model"`
+	Index  int             `json:"index"` // 0-based position among installed models
+	Total  int             `json:"total"`
+	Result *ModelBenchmark `json:"result,omitempty"` // set once the model is done
+}
+
+// benchmarks tracks the running BenchmarkModels so it can be cancelled.
+var benchmarks struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// BenchmarkModels transcribes a synthesized sample of the given length with
+// every installed model and returns the results, fastest first. Models that
+// fail are included with Error set. Cancel with CancelBenchmark.
+func (t *TranscribeService) BenchmarkModels(sampleSeconds float64) ([]ModelBenchmark, error) {
+	if sampleSeconds < minBenchmarkSeconds || sampleSeconds > maxBenchmarkSeconds {
+		return nil, fmt.Errorf("benchmark sample must be between %d and %d seconds", minBenchmarkSeconds, maxBenchmarkSeconds)
+	}
+	if !t.IsWhisperAvailable() {
+		return nil, fmt.Errorf("whisper-cpp is not installed. Please install it with: brew install whisper-cpp")
+	}
+
+	type installed struct{ name, path string }
+	var models []installed
+	for _, def := range modelCatalog() {
+		if p := locateModel(def.FileName); p != "" {
+			models = append(models, installed{def.Name, p})
+		}
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no models are installed")
+	}
+
+	ctx, cancel := context.WithCancel(t.runContext())
+	defer cancel()
+	benchmarks.mu.Lock()
+	if benchmarks.cancel != nil {
+		benchmarks.mu.Unlock()
+		return nil, fmt.Errorf("a benchmark is already running")
+	}
+	benchmarks.cancel = cancel
+	benchmarks.mu.Unlock()
+	defer func() {
+		benchmarks.mu.Lock()
+		benchmarks.cancel = nil
+		benchmarks.mu.Unlock()
+	}()
+
+	dir, err := os.MkdirTemp("", "meeting_benchmark_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	wavPath := filepath.Join(dir, "sample.wav")
+	if err := writePCMWAV(wavPath, benchmarkSample(sampleSeconds), outputSampleRate, 1); err != nil {
+		return nil, fmt.Errorf("failed to write benchmark sample: %w", err)
+	}
+
+	results := make([]ModelBenchmark, 0, len(models))
+	for i, m := range models {
+		application.Get().Event.Emit("transcribe:benchmark", BenchmarkProgress{Model: m.name, Index: i, Total: len(models)})
+
+		result := t.benchmarkModel(ctx, m.path, wavPath, sampleSeconds)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("benchmark cancelled")
+		}
+		result.Model = m.name
+		results = append(results, result)
+
+		application.Get().Event.Emit("transcribe:benchmark", BenchmarkProgress{Model: m.name, Index: i, Total: len(models), Result: &result})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		// Failed runs go last
+		if (results[i].Error == "") != (results[j].Error == "") {
+			return results[i].Error == ""
+		}
+		return results[i].RealTimeFactor < results[j].RealTimeFactor
+	})
+	return results, nil
+}
+
+// CancelBenchmark stops a running BenchmarkModels.
+func (t *TranscribeService) CancelBenchmark() {
+	benchmarks.mu.Lock()
+	defer benchmarks.mu.Unlock()
+	if benchmarks.cancel != nil {
+		benchmarks.cancel()
+	}
+}
+
+func (t *TranscribeService) benchmarkModel(ctx context.Context, modelPath, wavPath string, sampleSeconds float64) ModelBenchmark {
+	var result ModelBenchmark
+
+	language := t.config().language
+	if checkModelLanguage(modelPath, language) != nil {
+		language = "en"
+	}
+	args := whisperArgs(t.whisperVariant, modelPath, language, wavPath, false)
+
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, t.whisperBin, args...)
+	cmd.WaitDelay = whisperWaitDelay
+	output, err := cmd.CombinedOutput()
+	elapsed := time.Since(start)
+	if err != nil {
+		result.Error = fmt.Sprintf("whisper-cpp failed: %v\nOutput: %s", err, outputTail(output))
+		return result
+	}
+
+	result.Seconds = elapsed.Seconds()
+	result.RealTimeFactor = elapsed.Seconds() / sampleSeconds
+	result.PeakMemoryMB = float64(peakMemory(cmd.ProcessState)) / (1 << 20)
+	return result
+}
+
+// benchmarkSample synthesizes speech-like audio: a buzzy harmonic source with
+// drifting pitch, shaped into syllables and pauses. Whisper's runtime barely
+// depends on content, but pure silence lets it skip work and flatters fast
+// models.
+func benchmarkSample(seconds float64) []int16 {
+	rng := rand.New(rand.NewSource(1))
+	n := int(seconds * outputSampleRate)
+	samples := make([]int16, n)
+	phase := 0.0
+	for i := range samples {
+		t := float64(i) / outputSampleRate
+		pitch := 140 + 30*math.Sin(2*math.Pi*0.7*t)
+		phase += 2 * math.Pi * pitch / outputSampleRate
+
+		v := 0.0
+		for h := 1; h <= 8; h++ {
+			v += math.Sin(float64(h)*phase) / float64(h)
+		}
+		v += 0.1 * (rng.Float64()*2 - 1)
+
+		// ~4 syllables per second, with a short pause every 2 seconds
+		envelope := math.Max(0, math.Sin(2*math.Pi*2*t))
+		if math.Mod(t, 2) > 1.7 {
+			envelope = 0.02
+		}
+		samples[i] = int16(0.25 * envelope * v * 32767 / 2)
+	}
+	return samples
+}
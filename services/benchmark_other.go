@@ -0,0 +1,9 @@
+//go:build !darwin && !linux
+
+package services
+
+import "os"
+
+func peakMemory(*os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,22 @@
+//go:build darwin || linux
+
+package services
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// peakMemory returns the peak resident memory of an exited process in bytes.
+func peakMemory(state *os.ProcessState) int64 {
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// Linux reports ru_maxrss in kilobytes, macOS in bytes
+	if runtime.GOOS == "linux" {
+		return usage.Maxrss * 1024
+	}
+	return usage.Maxrss
+}
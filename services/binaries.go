@@ -0,0 +1,36 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// homebrewBinDirs are checked directly because macOS GUI apps don't inherit
+// the shell PATH.
+var homebrewBinDirs = []string{
+	"/opt/homebrew/bin", // Apple Silicon
+	"/usr/local/bin",    // Intel
+}
+
+// findExecutable returns the path of the first of names found on PATH or in
+// the Homebrew bin directories, or "" if none is installed.
+func findExecutable(names ...string) string {
+	// Try PATH first
+	for _, name := range names {
+		if p, err := exec.LookPath(name); err == nil {
+			return p
+		}
+	}
+
+	for _, dir := range homebrewBinDirs {
+		for _, name := range names {
+			p := filepath.Join(dir, name)
+			if _, err := os.Stat(p); err == nil {
+				return p
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// whisperOutputExts are the files whisper can write next to its input.
+var whisperOutputExts = []string{"txt", "json", "srt", "vtt"}
+
+// transcriptions tracks running whisper invocations so CancelTranscription
+// can stop them.
+var transcriptions struct {
+	mu      sync.Mutex
+	next    int
+	cancels map[int]context.CancelFunc
+}
+
+// CancelTranscription stops every whisper run in progress. The cancelled
+// calls return an error, and any output files whisper had started writing
+// are removed.
+func (t *TranscribeService) CancelTranscription() {
+	transcriptions.mu.Lock()
+	defer transcriptions.mu.Unlock()
+	for _, cancel := range transcriptions.cancels {
+		cancel()
+	}
+}
+
+// trackTranscription derives a context that CancelTranscription cancels.
+// The returned func must be called when the run ends.
+func trackTranscription(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	transcriptions.mu.Lock()
+	if transcriptions.cancels == nil {
+		transcriptions.cancels = make(map[int]context.CancelFunc)
+	}
+	id := transcriptions.next
+	transcriptions.next++
+	transcriptions.cancels[id] = cancel
+	transcriptions.mu.Unlock()
+
+	return ctx, func() {
+		transcriptions.mu.Lock()
+		delete(transcriptions.cancels, id)
+		transcriptions.mu.Unlock()
+		cancel()
+	}
+}
+
+// pendingWhisperOutputs returns the paths whisper may write for inputPath,
+// under either naming scheme (see whisperOutputPath), that don't exist yet.
+// Taken before launch, it lets a failed run's leftovers be removed without
+// touching files that were already there.
+func pendingWhisperOutputs(inputPath string) []string {
+	noExt := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+	var paths []string
+	for _, ext := range whisperOutputExts {
+		for _, p := range []string{inputPath + "." + ext, noExt + "." + ext} {
+			if _, err := os.Stat(p); os.IsNotExist(err) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// removeWhisperOutputs deletes whichever of paths whisper created.
+func removeWhisperOutputs(paths []string) {
+	for _, p := range paths {
+		os.Remove(p)
+	}
+}
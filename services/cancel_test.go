@@ -0,0 +1,46 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveWhisperOutputsKeepsExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	wavPath := filepath.Join(dir, "meeting.wav")
+	existing := filepath.Join(dir, "meeting.txt")
+	for _, p := range []string{wavPath, existing} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outputs := pendingWhisperOutputs(wavPath)
+
+	// What a cancelled run leaves behind, under both naming schemes
+	created := []string{
+		wavPath + ".txt",
+		wavPath + ".srt",
+		filepath.Join(dir, "meeting.srt"),
+		filepath.Join(dir, "meeting.json"),
+	}
+	for _, p := range created {
+		if err := os.WriteFile(p, []byte("partial"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removeWhisperOutputs(outputs)
+
+	for _, p := range created {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s was left behind", filepath.Base(p))
+		}
+	}
+	for _, p := range []string{wavPath, existing} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s was removed: %v", filepath.Base(p), err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// customModels holds the entries loaded by LoadModelCatalog, which add to
+// or replace the built-in modelDefinitions by name.
+var (
+	catalogMu    sync.Mutex
+	customModels []ModelInfo
+)
+
+// modelCatalog returns the built-in models with the custom catalog merged in
+// and any sizes fetched by RefreshCatalogSizes applied.
+func modelCatalog() []ModelInfo {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	models := make([]ModelInfo, len(modelDefinitions), len(modelDefinitions)+len(customModels))
+	copy(models, modelDefinitions)
+	for _, custom := range customModels {
+		replaced := false
+		for i := range models {
+			if models[i].Name == custom.Name {
+				models[i] = custom
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			models = append(models, custom)
+		}
+	}
+	for i := range models {
+		if n := remoteSizes[models[i].URL]; n > 0 {
+			models[i].Bytes = n
+			models[i].Size = formatSize(n)
+		}
+	}
+	return models
+}
+
+// findModelDefinition looks up a model in the merged catalog by name.
+func findModelDefinition(name string) (ModelInfo, bool) {
+	for _, def := range modelCatalog() {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// LoadModelCatalog reads a JSON array of models (name, fileName, size, url
+// and optionally sha256) and merges it into the built-in list, replacing
+// built-in entries with the same name. The path is saved so the catalog is
+// loaded again on startup; an empty path goes back to the built-in list.
+func (m *ModelService) LoadModelCatalog(path string) error {
+	var models []ModelInfo
+	if path != "" {
+		var err error
+		if models, err = readModelCatalog(path); err != nil {
+			return err
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return fmt.Errorf("invalid catalog path: %w", err)
+		}
+	}
+
+	if err := updateSettings(func(s *Settings) { s.ModelCatalogPath = path }); err != nil {
+		return fmt.Errorf("failed to save model catalog path: %w", err)
+	}
+	catalogMu.Lock()
+	customModels = models
+	catalogMu.Unlock()
+	return nil
+}
+
+// loadSavedModelCatalog loads the catalog saved by LoadModelCatalog. A
+// missing or invalid catalog is logged and the built-in list used, in
+// which case it returns false.
+func loadSavedModelCatalog() bool {
+	settings, err := loadSettings()
+	if err != nil {
+		log.Printf("failed to load settings: %v", err)
+		return false
+	}
+	if settings.ModelCatalogPath == "" {
+		return true
+	}
+	models, err := readModelCatalog(settings.ModelCatalogPath)
+	if err != nil {
+		log.Printf("ignoring model catalog: %v", err)
+		return false
+	}
+	catalogMu.Lock()
+	customModels = models
+	catalogMu.Unlock()
+	return true
+}
+
+func readModelCatalog(path string) ([]ModelInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model catalog: %w", err)
+	}
+	var models []ModelInfo
+	if err := json.Unmarshal(data, &models); err != nil {
+		return nil, fmt.Errorf("failed to parse model catalog: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range models {
+		model := &models[i]
+		if err := validateModelInfo(model); err != nil {
+			return nil, fmt.Errorf("model catalog entry %d: %w", i+1, err)
+		}
+		if seen[model.Name] {
+			return nil, fmt.Errorf("model catalog lists %q more than once", model.Name)
+		}
+		seen[model.Name] = true
+		model.Exists = false
+	}
+	return models, nil
+}
+
+// validateModelInfo checks a catalog entry's required fields and
+// normalizes its checksum to lower case.
+func validateModelInfo(model *ModelInfo) error {
+	model.Name = strings.TrimSpace(model.Name)
+	if model.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if model.FileName == "" {
+		return fmt.Errorf("%s: fileName is required", model.Name)
+	}
+	// The file name is joined to the models directory, so it must not escape it
+	if filepath.Base(model.FileName) != model.FileName || model.FileName == "." || model.FileName == ".." {
+		return fmt.Errorf("%s: fileName must be a plain file name, got %q", model.Name, model.FileName)
+	}
+
+	u, err := url.Parse(model.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%s: url must be an http(s) URL, got %q", model.Name, model.URL)
+	}
+
+	if model.SHA256 != "" {
+		model.SHA256 = strings.ToLower(model.SHA256)
+		if _, err := hex.DecodeString(model.SHA256); err != nil || len(model.SHA256) != 64 {
+			return fmt.Errorf("%s: sha256 must be 64 hex characters", model.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultChunkSeconds   = 300
+	defaultOverlapSeconds = 2
+	// chunkPromptChars is how much of the previous chunk's text is passed
+	// to whisper as --prompt
+	chunkPromptChars = 200
+	// Bounds of the text de-duplication search, in characters
+	minOverlapRunes = 4
+	maxOverlapRunes = 300
+)
+
+// ChunkConfig controls how TranscribeChunked splits long recordings.
+type ChunkConfig struct {
+	ChunkSeconds   float64 `json:"chunkSeconds"`
+	OverlapSeconds float64 `json:"overlapSeconds"` // shared by adjacent chunks
+}
+
+// SetChunkConfig sets the chunk length and the overlap between chunks used
+// by TranscribeChunked. Too little overlap can cut words at the seams; too
+// much repeats work. Both must be positive, with the overlap shorter than a
+// chunk.
+func (t *TranscribeService) SetChunkConfig(cfg ChunkConfig) error {
+	if cfg.ChunkSeconds <= 0 || cfg.OverlapSeconds <= 0 {
+		return fmt.Errorf("chunk and overlap lengths must be positive")
+	}
+	if cfg.OverlapSeconds >= cfg.ChunkSeconds {
+		return fmt.Errorf("overlap (%gs) must be shorter than a chunk (%gs)", cfg.OverlapSeconds, cfg.ChunkSeconds)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.chunks = cfg
+	return nil
+}
+
+func (c transcribeConfig) chunkConfig() ChunkConfig {
+	if c.chunks.ChunkSeconds == 0 {
+		return ChunkConfig{ChunkSeconds: defaultChunkSeconds, OverlapSeconds: defaultOverlapSeconds}
+	}
+	return c.chunks
+}
+
+// TranscribeChunked transcribes a long WAV in overlapping chunks (see
+// SetChunkConfig), which bounds whisper's memory use and time per run.
+// Each chunk is prompted with the end of the previous chunk's text for
+// continuity, and text repeated in the overlaps is removed.
+func (t *TranscribeService) TranscribeChunked(wavPath string) (string, error) {
+	result, err := t.transcribeChunked(t.config(), wavPath)
+	return result.Text, err
+}
+
+// TranscribeChunkedToFile is TranscribeChunked saving the result like
+// TranscribeToFileResult. Chunks detect their language separately, so with
+// SetLanguageTags a meeting that switches language is annotated where it
+// does.
+func (t *TranscribeService) TranscribeChunkedToFile(wavPath string, meta MeetingMeta) (TranscribeResult, error) {
+	meta, err := cleanMeetingMeta(meta)
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	cfg := t.config()
+	if err := cfg.checkMinLength(wavPath); err != nil {
+		return TranscribeResult{}, err
+	}
+	result, err := t.transcribeChunked(cfg, wavPath)
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	return saveTranscription(cfg, wavPath, meta, result)
+}
+
+// transcribeChunked runs TranscribeChunked with the settings in cfg,
+// returning the merged segments along with the text.
+func (t *TranscribeService) transcribeChunked(cfg transcribeConfig, wavPath string) (transcription, error) {
+	samples, info, err := readWAV(wavPath)
+	if err != nil {
+		return transcription{}, fmt.Errorf("failed to read %s: %w", wavPath, err)
+	}
+	samples = mixToMono(samples, info.numChannels)
+
+	chunks := cfg.chunkConfig()
+	sr := info.sampleRate
+	chunkLen := int(chunks.ChunkSeconds * float64(sr))
+	step := max(chunkLen-int(chunks.OverlapSeconds*float64(sr)), 1)
+	usePrompt := t.supportsFlag("--prompt")
+
+	total := 1
+	if len(samples) > chunkLen {
+		total += (len(samples) - chunkLen + step - 1) / step
+	}
+	progress := cfg.newJobReporter("chunked", wavPath, total)
+	defer progress.done()
+
+	var merged transcription
+	for i, start := 0, 0; start < len(samples); i, start = i+1, start+step {
+		end := min(start+chunkLen, len(samples))
+		offset := float64(start) / float64(sr)
+
+		var extra []string
+		if usePrompt && merged.Text != "" {
+			extra = []string{"--prompt", textTail(merged.Text, chunkPromptChars)}
+		}
+		chunk, err := t.transcribeChunk(cfg, samples[start:end], sr, extra)
+		if err != nil {
+			return transcription{}, fmt.Errorf("chunk at %.0fs: %w", offset, err)
+		}
+
+		for i := range chunk.Segments {
+			chunk.Segments[i].Start += offset
+			chunk.Segments[i].End += offset
+		}
+		if start == 0 {
+			merged = chunk
+		} else {
+			// Switch chunks halfway through the overlap
+			boundary := offset + chunks.OverlapSeconds/2
+			merged.Segments = mergeChunkSegments(merged.Segments, chunk.Segments, boundary)
+			merged.Text = mergeOverlapText(merged.Text, chunk.Text)
+		}
+		progress.finished(i, "")
+		if end == len(samples) {
+			break
+		}
+	}
+
+	if len(merged.Segments) > 0 {
+		merged.Text = joinSegmentText(merged.Segments)
+	}
+	t.mu.Lock()
+	t.lastTranscript = merged.Text
+	t.detectedLanguages = segmentLanguages(merged.Segments, merged.Language)
+	t.mu.Unlock()
+	return merged, nil
+}
+
+// transcribeChunk writes samples, at sr, to a temporary WAV and transcribes
+// it.
+func (t *TranscribeService) transcribeChunk(cfg transcribeConfig, samples []int16, sr int, extra []string) (transcription, error) {
+	tmp, err := os.CreateTemp("", "meeting_chunk_*.wav")
+	if err != nil {
+		return transcription{}, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := tmp.Name()
+	tmp.Close()
+	defer os.Remove(path)
+
+	if err := writeChunkWAV(path, samples, sr); err != nil {
+		return transcription{}, fmt.Errorf("failed to write chunk: %w", err)
+	}
+	return t.transcribeContext(t.runContext(), cfg, path, extra...)
+}
+
+// writeChunkWAV writes mono samples recorded at sr to path at
+// outputSampleRate, the rate whisper is given everywhere else.
+func writeChunkWAV(path string, samples []int16, sr int) error {
+	samples = resample(samples, float64(sr), outputSampleRate, nil)
+	return writePCMWAV(path, samples, outputSampleRate, channels)
+}
+
+// mergeChunkSegments joins the segments of adjacent chunks, which overlap
+// in time: prev supplies segments centred before boundary and next those
+// centred at or after it. A segment repeated on both sides of the seam is
+// kept once.
+func mergeChunkSegments(prev, next []Segment, boundary float64) []Segment {
+	mid := func(s Segment) float64 { return (s.Start + s.End) / 2 }
+	out := make([]Segment, 0, len(prev)+len(next))
+	for _, s := range prev {
+		if mid(s) < boundary {
+			out = append(out, s)
+		}
+	}
+	for _, s := range next {
+		if mid(s) < boundary {
+			continue
+		}
+		if n := len(out); n > 0 && strings.TrimSpace(out[n-1].Text) == strings.TrimSpace(s.Text) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// mergeOverlapText appends next to prev, dropping the longest run of text
+// that ends prev and also starts next, as transcribed from the overlap.
+// Matching is by character so it works for unspaced CJK text too; runs
+// shorter than minOverlapRunes are treated as coincidence.
+func mergeOverlapText(prev, next string) string {
+	prev, next = strings.TrimSpace(prev), strings.TrimSpace(next)
+	if prev == "" || next == "" {
+		return prev + next
+	}
+
+	a, b := []rune(prev), []rune(next)
+	for k := min(len(a), len(b), maxOverlapRunes); k >= minOverlapRunes; k-- {
+		if string(a[len(a)-k:]) == string(b[:k]) {
+			next = strings.TrimSpace(string(b[k:]))
+			break
+		}
+	}
+	if next == "" {
+		return prev
+	}
+	if last, _ := utf8.DecodeLastRuneInString(prev); isCJK(last) {
+		return prev + next
+	}
+	return prev + " " + next
+}
+
+// textTail returns roughly the last n bytes of text without splitting a
+// UTF-8 sequence.
+func textTail(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	i := len(text) - n
+	for i < len(text) && !utf8.RuneStart(text[i]) {
+		i++
+	}
+	return strings.TrimSpace(text[i:])
+}
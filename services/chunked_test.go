@@ -0,0 +1,80 @@
+package services
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteChunkWAVResamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chunk.wav")
+	if err := writeChunkWAV(path, tone(48000, 48000, 440, 8000), 48000); err != nil {
+		t.Fatal(err)
+	}
+	samples, info, err := readWAV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.sampleRate != outputSampleRate || info.numChannels != 1 {
+		t.Errorf("chunk written at %dHz with %d channels, want %dHz mono", info.sampleRate, info.numChannels, outputSampleRate)
+	}
+	if len(samples) != outputSampleRate {
+		t.Errorf("one second written as %d samples, want %d", len(samples), outputSampleRate)
+	}
+}
+
+func TestMergeChunkSegments(t *testing.T) {
+	prev := []Segment{
+		{Start: 0, End: 10, Text: "first"},
+		{Start: 10, End: 20, Text: "second"},
+		{Start: 26, End: 32, Text: "straddles the seam"},
+	}
+	next := []Segment{
+		{Start: 25, End: 28, Text: "overlap, before the seam"},
+		{Start: 27, End: 33, Text: " straddles the seam "},
+		{Start: 33, End: 40, Text: "third"},
+	}
+
+	got := mergeChunkSegments(prev, next, 30)
+	want := []Segment{
+		{Start: 0, End: 10, Text: "first"},
+		{Start: 10, End: 20, Text: "second"},
+		{Start: 26, End: 32, Text: "straddles the seam"},
+		{Start: 33, End: 40, Text: "third"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeChunkSegments() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeChunkSegmentsDropsRepeatAcrossSeam(t *testing.T) {
+	prev := []Segment{{Start: 20, End: 29, Text: "said twice"}}
+	next := []Segment{{Start: 30, End: 31, Text: "said twice"}, {Start: 31, End: 35, Text: "then more"}}
+
+	got := mergeChunkSegments(prev, next, 30)
+	want := []Segment{{Start: 20, End: 29, Text: "said twice"}, {Start: 31, End: 35, Text: "then more"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeChunkSegments() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeOverlapText(t *testing.T) {
+	tests := []struct {
+		name, prev, next, want string
+	}{
+		{"overlap removed", "we should ship it on Friday", "on Friday after the review", "we should ship it on Friday after the review"},
+		{"no overlap", "first part.", "second part.", "first part. second part."},
+		{"short match is coincidence", "ends in abc", "abc starts", "ends in abc abc starts"},
+		{"next entirely overlap", "the whole thing", "whole thing", "the whole thing"},
+		{"empty prev", "", " next ", "next"},
+		{"empty next", " prev ", "", "prev"},
+		{"cjk joined without space", "今日は会議があります", "会議があります。明日も", "今日は会議があります。明日も"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeOverlapText(tt.prev, tt.next); got != tt.want {
+				t.Errorf("mergeOverlapText(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+			}
+		})
+	}
+}
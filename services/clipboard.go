@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// CopyToClipboard puts text on the system clipboard.
+func (t *TranscribeService) CopyToClipboard(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("nothing to copy: the text is empty")
+	}
+	if !application.Get().Clipboard.SetText(text) {
+		return fmt.Errorf("failed to copy to clipboard")
+	}
+	return nil
+}
+
+// CopyLastTranscript copies the most recent transcription result so it can
+// be pasted without opening the saved file.
+func (t *TranscribeService) CopyLastTranscript() error {
+	t.mu.Lock()
+	text := t.lastTranscript
+	t.mu.Unlock()
+	if text == "" {
+		return fmt.Errorf("no transcript to copy yet")
+	}
+	return t.CopyToClipboard(text)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SetCompressedTemp saves recordings handed to transcription as FLAC instead
+// of WAV, which is roughly half the size, for multi-hour sessions on small
+// disks. FLAC is lossless, so transcription quality is unchanged; the audio
+// is decoded to a temporary WAV only while whisper runs. Requires ffmpeg;
+// without it recordings are saved as WAV as before.
+func (a *AudioService) SetCompressedTemp(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.compressedTemp = enabled
+}
+
+// writeTempAudio writes the transcription audio to base plus ".flac" when
+// compressed temp files are enabled and ffmpeg works, or ".wav" otherwise.
+// Returns the written path and the sampleHash of the audio. trim applies
+// SetTrimEdges.
+func writeTempAudio(s audioSnapshot, base string, trim bool) (path, hash string, err error) {
+	samples, sr := s.transcriptionSamples(trim), s.sr
+	hash = sampleHash(samples)
+
+	if s.compressed {
+		flacPath := base + ".flac"
+		err := exportFLAC(flacPath, samples, sr, channels, bitDepth)
+		if err == nil {
+			return flacPath, hash, nil
+		}
+		log.Printf("saving uncompressed recording instead: %v", err)
+	}
+
+	wavPath := base + ".wav"
+	if err := writePCMWAV(wavPath, samples, sr, channels); err != nil {
+		return "", "", err
+	}
+	return wavPath, hash, nil
+}
+
+// isCompressedAudio reports whether path needs decoding before whisper.
+func isCompressedAudio(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".flac")
+}
+
+// decodeToWAV converts a compressed recording to a temporary 16-bit WAV for
+// whisper. The caller must remove the returned file.
+func decodeToWAV(path string) (string, error) {
+	ffmpeg := findExecutable("ffmpeg")
+	if ffmpeg == "" {
+		return "", fmt.Errorf("ffmpeg is required to read %s. Please install it with: brew install ffmpeg", filepath.Base(path))
+	}
+
+	tmp, err := os.CreateTemp("", "meeting_decoded_*.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	wavPath := tmp.Name()
+	tmp.Close()
+
+	cmd := exec.Command(ffmpeg, "-y", "-loglevel", "error", "-i", path, "-c:a", "pcm_s16le", wavPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(wavPath)
+		return "", fmt.Errorf("failed to decode %s: %w\nOutput: %s", filepath.Base(path), err, string(output))
+	}
+	return wavPath, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// settingsExportVersion is bumped when the export format changes
+// incompatibly.
+const settingsExportVersion = 1
+
+// settingsExport is the document written by ExportSettings.
+type settingsExport struct {
+	Version  int      `json:"version"`
+	Settings Settings `json:"settings"`
+}
+
+// SettingsService backs up and restores the persisted settings shared by
+// the other services, applying restored values to them straight away.
+type SettingsService struct {
+	transcribe *TranscribeService
+}
+
+func NewSettingsService(transcribe *TranscribeService) *SettingsService {
+	return &SettingsService{transcribe: transcribe}
+}
+
+func (s *SettingsService) ServiceName() string {
+	return "SettingsService"
+}
+
+func (s *SettingsService) ServiceStartup(_ context.Context, _ application.ServiceOptions) error {
+	return nil
+}
+
+func (s *SettingsService) ServiceShutdown() error {
+	return nil
+}
+
+// ExportSettings returns the persisted configuration as JSON: replacements
+// and their options, the per-language model map and the model catalog
+// path. Machine-specific state like recent devices and the last recording
+// is left out.
+func (s *SettingsService) ExportSettings() ([]byte, error) {
+	settings, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	settings.RecentInputDevices = nil
+	settings.LastRecording = nil
+
+	data, err := json.MarshalIndent(settingsExport{Version: settingsExportVersion, Settings: settings}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	return data, nil
+}
+
+// ImportSettings validates and saves settings produced by ExportSettings,
+// replacing the exported fields and keeping this machine's own state. The
+// model catalog is reloaded and the transcriber picks up the replacements
+// and model map, switching models if the current language is mapped.
+func (s *SettingsService) ImportSettings(data []byte) error {
+	var doc settingsExport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse settings: %w", err)
+	}
+	if doc.Version != settingsExportVersion {
+		return fmt.Errorf("unsupported settings version %d", doc.Version)
+	}
+	in := doc.Settings
+
+	if _, err := compileReplacements(in.Replacements, in.ReplacementOptions); err != nil {
+		return fmt.Errorf("invalid replacements: %w", err)
+	}
+	models, err := cleanLanguageModels(in.LanguageModels)
+	if err != nil {
+		return fmt.Errorf("invalid language models: %w", err)
+	}
+	var catalog []ModelInfo
+	if in.ModelCatalogPath != "" {
+		if catalog, err = readModelCatalog(in.ModelCatalogPath); err != nil {
+			return err
+		}
+		if in.ModelCatalogPath, err = filepath.Abs(in.ModelCatalogPath); err != nil {
+			return fmt.Errorf("invalid catalog path: %w", err)
+		}
+	}
+
+	err = updateSettings(func(cur *Settings) {
+		cur.Replacements = in.Replacements
+		cur.ReplacementOptions = in.ReplacementOptions
+		cur.LanguageModels = models
+		cur.ModelCatalogPath = in.ModelCatalogPath
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	catalogMu.Lock()
+	customModels = catalog
+	catalogMu.Unlock()
+
+	t := s.transcribe
+	t.loadSettings()
+	t.setModelPath(t.config().findModelPath())
+	return nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sampleHash is a fast, non-cryptographic fingerprint of the transcription
+// audio, used to notice the same recording being transcribed twice.
+func sampleHash(samples []int16) string {
+	h := fnv.New64a()
+	buf := make([]byte, 0, 64*1024)
+	for i, s := range samples {
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(s))
+		if len(buf) == cap(buf) || i == len(samples)-1 {
+			h.Write(buf)
+			buf = buf[:0]
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// audioFileHash returns the sampleHash of a saved recording, decoding FLAC
+// first so it matches the hash taken when the recording was written.
+func audioFileHash(path string) (string, error) {
+	if isCompressedAudio(path) {
+		decoded, err := decodeToWAV(path)
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(decoded)
+		path = decoded
+	}
+	samples, _, err := readWAV(path)
+	if err != nil {
+		return "", err
+	}
+	return sampleHash(samples), nil
+}
+
+// FindDuplicateRecording returns the markdown of an earlier transcript of
+// the same audio as wavPath, or "" if it hasn't been transcribed before.
+func (t *TranscribeService) FindDuplicateRecording(wavPath string) (string, error) {
+	hash := ""
+	if info, ok := recordings.get(recordings.idForPath(wavPath)); ok {
+		hash = info.AudioHash
+	}
+	if hash == "" {
+		var err error
+		if hash, err = audioFileHash(wavPath); err != nil {
+			return "", fmt.Errorf("cannot read recording: %w", err)
+		}
+	}
+
+	saveDir, err := transcriptionsDir()
+	if err != nil {
+		return "", err
+	}
+	sidecars, err := filepath.Glob(filepath.Join(saveDir, "*"+transcriptSuffix))
+	if err != nil {
+		return "", err
+	}
+	for _, p := range sidecars {
+		tf, err := readTranscriptFile(p)
+		if err != nil || tf.AudioHash != hash {
+			continue
+		}
+		mdPath := strings.TrimSuffix(p, transcriptSuffix) + ".md"
+		if _, err := os.Stat(mdPath); err == nil {
+			return mdPath, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// InputDeviceInfo describes an audio input device for the frontend device picker.
+type InputDeviceInfo struct {
+	Index      int     `json:"index"`
+	Name       string  `json:"name"`
+	SampleRate float64 `json:"sampleRate"`
+	Present    bool    `json:"present"`
+}
+
+func newInputDeviceInfo(dev *portaudio.DeviceInfo) InputDeviceInfo {
+	return InputDeviceInfo{
+		Index:      dev.Index,
+		Name:       dev.Name,
+		SampleRate: dev.DefaultSampleRate,
+		Present:    true,
+	}
+}
+
+// standardSampleRates are the rates real input hardware runs at. Anything
+// else usually comes from a misconfigured virtual device.
+var standardSampleRates = []float64{8000, 11025, 16000, 22050, 24000, 32000, 44100, 48000, 88200, 96000, 176400, 192000}
+
+// SampleRateWarning is emitted as "audio:sample-rate-warning" when the input
+// device's rate is likely to hurt transcription. Recording continues.
+type SampleRateWarning struct {
+	RecordingID string  `json:"recordingId"`
+	DeviceName  string  `json:"deviceName"`
+	SampleRate  float64 `json:"sampleRate"`
+	Message     string  `json:"message"`
+}
+
+// sampleRateProblem describes what's wrong with recording at rate for a
+// transcription WAV at outputRate, or returns "" if it's fine.
+func sampleRateProblem(rate float64, outputRate int) string {
+	if rate < float64(outputRate) {
+		return fmt.Sprintf("the input device records at %.0f Hz, below the %d Hz used for transcription; upsampling can't restore the missing high frequencies, so accuracy will suffer. Try another input device", rate, outputRate)
+	}
+	if !slices.Contains(standardSampleRates, rate) {
+		return fmt.Sprintf("the input device reports an unusual sample rate of %.0f Hz, which often means a misconfigured virtual device. If transcripts come out poor, try another input device", rate)
+	}
+	return ""
+}
+
+// HostAPIInfo describes an audio backend such as CoreAudio or JACK.
+type HostAPIInfo struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	DeviceCount int    `json:"deviceCount"`
+	Selected    bool   `json:"selected"`
+}
+
+// ListHostAPIs returns the audio backends portaudio can use.
+func (a *AudioService) ListHostAPIs() ([]HostAPIInfo, error) {
+	// a.mu keeps refreshDevices from re-initializing portaudio meanwhile
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	hosts, err := portaudio.HostApis()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list host APIs: %w", err)
+	}
+	current, err := hostAPIOrDefault(a.selectedHost)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]HostAPIInfo, len(hosts))
+	for i, h := range hosts {
+		result[i] = HostAPIInfo{
+			Index:       i,
+			Name:        h.Name,
+			DeviceCount: len(h.Devices),
+			Selected:    h == current,
+		}
+	}
+	return result, nil
+}
+
+// SelectHostAPI switches device enumeration and recording to another audio
+// backend. The selected input device is reset to the backend's default.
+// An invalid index reverts to the system default backend.
+func (a *AudioService) SelectHostAPI(index int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	hosts, err := portaudio.HostApis()
+	if err != nil {
+		return fmt.Errorf("failed to list host APIs: %w", err)
+	}
+	a.selectedDevice = nil
+	if index < 0 || index >= len(hosts) {
+		a.selectedHost = nil
+		return fmt.Errorf("invalid host API index %d; using the default", index)
+	}
+	a.selectedHost = hosts[index]
+	return nil
+}
+
+// ListInputDevices returns the selected host API's devices that can capture
+// audio, including ones connected since the last call (see refreshDevices).
+// It's the only call that refreshes devices, so the indices it returns stay
+// valid until it's called again.
+func (a *AudioService) ListInputDevices() ([]InputDeviceInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.refreshDevices(); err != nil {
+		return nil, err
+	}
+	return a.inputDevices()
+}
+
+// inputDevices lists the selected host API's input devices as last
+// enumerated, without refreshing. Callers must hold a.mu.
+func (a *AudioService) inputDevices() ([]InputDeviceInfo, error) {
+	host, err := hostAPIOrDefault(a.selectedHost)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []InputDeviceInfo
+	for _, dev := range host.Devices {
+		if dev.MaxInputChannels > 0 {
+			result = append(result, newInputDeviceInfo(dev))
+		}
+	}
+	return result, nil
+}
+
+// SelectInputDevice chooses the device used by the next StartRecording.
+// index is from the last ListInputDevices, so devices aren't refreshed here.
+func (a *AudioService) SelectInputDevice(index int) error {
+	a.mu.Lock()
+	devs, err := portaudio.Devices()
+	if err != nil {
+		a.mu.Unlock()
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+	if index < 0 || index >= len(devs) {
+		a.mu.Unlock()
+		return fmt.Errorf("invalid device index: %d", index)
+	}
+	dev := devs[index]
+	if dev.MaxInputChannels < 1 {
+		a.mu.Unlock()
+		return fmt.Errorf("device %q has no input channels", dev.Name)
+	}
+	host, err := hostAPIOrDefault(a.selectedHost)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	if dev.HostApi != host {
+		a.mu.Unlock()
+		return fmt.Errorf("device %q isn't available through %s", dev.Name, host.Name)
+	}
+	info := newInputDeviceInfo(dev)
+	a.selectedDevice = &info
+	a.mu.Unlock()
+
+	rememberInputDevice(dev.Name)
+	return nil
+}
+
+// GetSelectedInputDevice returns the device recording will use. When the
+// selected device has disappeared, the last known info is returned with
+// Present set to false instead of an error. Devices are as of the last
+// ListInputDevices, since this is polled for status and mustn't renumber
+// them.
+func (a *AudioService) GetSelectedInputDevice() (InputDeviceInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.selectedDevice == nil {
+		dev, err := defaultInputDevice(a.selectedHost)
+		if err != nil {
+			return InputDeviceInfo{}, err
+		}
+		return newInputDeviceInfo(dev), nil
+	}
+
+	dev, err := findInputDevice(*a.selectedDevice)
+	if err != nil {
+		return InputDeviceInfo{}, err
+	}
+	if dev == nil {
+		info := *a.selectedDevice
+		info.Present = false
+		return info, nil
+	}
+	return newInputDeviceInfo(dev), nil
+}
+
+// refreshDevices re-initializes portaudio, which only enumerates devices
+// when initialized, so ones plugged in or removed since show up. It's
+// skipped unless idle, since terminating would pull an open stream out from
+// under the device; the selected host API is looked up again by type.
+// Callers must hold a.mu.
+func (a *AudioService) refreshDevices() error {
+	if a.state != stateIdle || a.stream != nil || a.streamStuck {
+		return nil
+	}
+	if err := portaudio.Terminate(); err != nil {
+		return fmt.Errorf("failed to refresh devices: %w", err)
+	}
+	if err := portaudio.Initialize(); err != nil {
+		return fmt.Errorf("failed to refresh devices: %w", err)
+	}
+	if a.selectedHost != nil {
+		// nil, if the backend went away, means the default
+		a.selectedHost, _ = portaudio.HostApi(a.selectedHost.Type)
+	}
+	return nil
+}
+
+// IsFormatSupported reports whether the device can record mono 16-bit or
+// float audio at its native sample rate. When it can't, the error says why
+// so the UI can explain why the device is disabled.
+func (a *AudioService) IsFormatSupported(deviceIndex int) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	devs, err := portaudio.Devices()
+	if err != nil {
+		return false, fmt.Errorf("failed to list devices: %w", err)
+	}
+	if deviceIndex < 0 || deviceIndex >= len(devs) {
+		return false, fmt.Errorf("invalid device index: %d", deviceIndex)
+	}
+	dev := devs[deviceIndex]
+	if dev.MaxInputChannels < 1 {
+		return false, fmt.Errorf("device %q is output-only", dev.Name)
+	}
+
+	params := inputStreamParams(dev, channels)
+	if err := portaudio.IsFormatSupported(params, func(in []int16) {}); err != nil {
+		// openInputStream falls back to float32 and converts
+		if portaudio.IsFormatSupported(params, func(in []float32) {}) == nil {
+			return true, nil
+		}
+		return false, fmt.Errorf("device %q can't record mono 16-bit audio at %.0f Hz: %w", dev.Name, dev.DefaultSampleRate, err)
+	}
+	return true, nil
+}
+
+// inputStreamParams returns the stream parameters used for recording from dev.
+func inputStreamParams(dev *portaudio.DeviceInfo, numChannels int) portaudio.StreamParameters {
+	params := portaudio.HighLatencyParameters(dev, nil)
+	params.Input.Channels = numChannels
+	params.SampleRate = dev.DefaultSampleRate
+	params.FramesPerBuffer = bufferSize
+	return params
+}
+
+// recordingChannels returns the channel count to record from dev, falling
+// back to mono when the device can't provide the requested count.
+func recordingChannels(dev *portaudio.DeviceInfo, requested int) int {
+	if requested < 1 || requested > dev.MaxInputChannels {
+		return channels
+	}
+	return requested
+}
+
+// resolveInputDevice returns the portaudio device to record from.
+// Callers must hold a.mu.
+func (a *AudioService) resolveInputDevice() (*portaudio.DeviceInfo, error) {
+	if a.selectedDevice == nil {
+		return defaultInputDevice(a.selectedHost)
+	}
+
+	dev, err := findInputDevice(*a.selectedDevice)
+	if err != nil {
+		return nil, err
+	}
+	if dev == nil {
+		return nil, fmt.Errorf("selected input device %q is no longer available", a.selectedDevice.Name)
+	}
+	return dev, nil
+}
+
+// hostAPIOrDefault returns selected, or the system default host API if nil.
+func hostAPIOrDefault(selected *portaudio.HostApiInfo) (*portaudio.HostApiInfo, error) {
+	if selected != nil {
+		return selected, nil
+	}
+	host, err := portaudio.DefaultHostApi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default host API: %w", err)
+	}
+	return host, nil
+}
+
+func defaultInputDevice(selectedHost *portaudio.HostApiInfo) (*portaudio.DeviceInfo, error) {
+	host, err := hostAPIOrDefault(selectedHost)
+	if err != nil {
+		return nil, err
+	}
+	dev := host.DefaultInputDevice
+	if dev == nil {
+		return nil, fmt.Errorf("no default input device found")
+	}
+	return dev, nil
+}
+
+// findInputDevice looks up a previously selected device. Device indices are
+// not stable across reconnects, so matching is done by name, preferring the
+// original index when several devices share a name. Returns nil when the
+// device is no longer present.
+func findInputDevice(info InputDeviceInfo) (*portaudio.DeviceInfo, error) {
+	devs, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	var match *portaudio.DeviceInfo
+	for _, dev := range devs {
+		if dev.Name != info.Name || dev.MaxInputChannels < 1 {
+			continue
+		}
+		if dev.Index == info.Index {
+			return dev, nil
+		}
+		if match == nil {
+			match = dev
+		}
+	}
+	return match, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxDiffCells caps the LCS table size; larger inputs are diffed by line
+// instead of by word to bound memory.
+const maxDiffCells = 4_000_000
+
+// DiffChunk is a run of text that is unchanged, added or removed.
+type DiffChunk struct {
+	Op   string `json:"op"` // "equal", "insert" or "delete"
+	Text string `json:"text"`
+}
+
+// diffTokenPattern splits text into whitespace runs, single CJK characters
+// and other words, so joining tokens reproduces the original text exactly.
+var diffTokenPattern = regexp.MustCompile(`\s+|[\p{Han}\p{Hiragana}\p{Katakana}]|[^\s\p{Han}\p{Hiragana}\p{Katakana}]+`)
+
+// TranscribeAndDiff transcribes wavPath and diffs the result against a
+// previous transcript, so users can judge whether a new model did better.
+func (t *TranscribeService) TranscribeAndDiff(wavPath, previousText string) (string, []DiffChunk, error) {
+	newText, err := t.Transcribe(wavPath)
+	if err != nil {
+		return "", nil, err
+	}
+	return newText, diffText(previousText, newText), nil
+}
+
+// diffText returns a word-level diff from a to b.
+func diffText(a, b string) []DiffChunk {
+	ta := diffTokenPattern.FindAllString(a, -1)
+	tb := diffTokenPattern.FindAllString(b, -1)
+	if len(ta)*len(tb) > maxDiffCells {
+		ta = strings.SplitAfter(a, "\n")
+		tb = strings.SplitAfter(b, "\n")
+	}
+	return diffTokens(ta, tb)
+}
+
+// diffTokens computes an LCS-based diff and merges adjacent tokens with the
+// same operation into chunks.
+func diffTokens(a, b []string) []DiffChunk {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var chunks []DiffChunk
+	add := func(op, text string) {
+		if text == "" {
+			// SplitAfter leaves an empty last line
+			return
+		}
+		if n := len(chunks); n > 0 && chunks[n-1].Op == op {
+			chunks[n-1].Text += text
+			return
+		}
+		chunks = append(chunks, DiffChunk{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add("equal", a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add("delete", a[i])
+			i++
+		default:
+			add("insert", b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add("delete", a[i])
+	}
+	for ; j < len(b); j++ {
+		add("insert", b[j])
+	}
+	return chunks
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffChunk
+	}{
+		{
+			name: "identical",
+			a:    "same text", b: "same text",
+			want: []DiffChunk{{"equal", "same text"}},
+		},
+		{
+			name: "word replaced",
+			a:    "the quick fox", b: "the slow fox",
+			want: []DiffChunk{{"equal", "the "}, {"delete", "quick"}, {"insert", "slow"}, {"equal", " fox"}},
+		},
+		{
+			name: "words added",
+			a:    "hello world", b: "hello big wide world",
+			want: []DiffChunk{{"equal", "hello "}, {"insert", "big wide "}, {"equal", "world"}},
+		},
+		{
+			name: "cjk by character",
+			a:    "今日は会議", b: "明日は会議",
+			want: []DiffChunk{{"delete", "今"}, {"insert", "明"}, {"equal", "日は会議"}},
+		},
+		{
+			name: "from empty",
+			a:    "", b: "new",
+			want: []DiffChunk{{"insert", "new"}},
+		},
+		{
+			name: "to empty",
+			a:    "old", b: "",
+			want: []DiffChunk{{"delete", "old"}},
+		},
+		{
+			name: "both empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffText(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffText(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffTextReconstructs checks that equal and deleted chunks rebuild the
+// old text and equal and inserted chunks the new one.
+func TestDiffTextReconstructs(t *testing.T) {
+	pairs := [][2]string{
+		{"we agreed to ship on Friday\nthen review", "we agreed to ship Monday\n\nthen  review it"},
+		{"会議は 10時 から", "会議は11時からです"},
+		{"a b c d e", "e d c b a"},
+	}
+	for _, p := range pairs {
+		var oldText, newText strings.Builder
+		for _, c := range diffText(p[0], p[1]) {
+			switch c.Op {
+			case "equal":
+				oldText.WriteString(c.Text)
+				newText.WriteString(c.Text)
+			case "delete":
+				oldText.WriteString(c.Text)
+			case "insert":
+				newText.WriteString(c.Text)
+			default:
+				t.Fatalf("unknown op %q", c.Op)
+			}
+		}
+		if oldText.String() != p[0] || newText.String() != p[1] {
+			t.Errorf("diffText(%q, %q) rebuilds %q and %q", p[0], p[1], oldText.String(), newText.String())
+		}
+	}
+}
+
+func TestDiffTextFallsBackToLines(t *testing.T) {
+	// Enough words that a word-level table would exceed maxDiffCells
+	line := strings.Repeat("word ", 500) + "\n"
+	a := strings.Repeat(line, 5) + "old ending\n"
+	b := strings.Repeat(line, 5) + "new ending\n"
+
+	got := diffText(a, b)
+	want := []DiffChunk{{"equal", strings.Repeat(line, 5)}, {"delete", "old ending\n"}, {"insert", "new ending\n"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffText() fell back to %d chunks, want whole-line chunks", len(got))
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ModelDiskUsage is one catalog model's size: on disk if installed,
+// otherwise the size of its download.
+type ModelDiskUsage struct {
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+	Bytes     int64  `json:"bytes"` // 0 if the catalog size can't be parsed
+}
+
+// ModelDiskReport summarizes how much space models use and need.
+type ModelDiskReport struct {
+	Models         []ModelDiskUsage `json:"models"`
+	InstalledCount int              `json:"installedCount"`
+	TotalCount     int              `json:"totalCount"`
+	InstalledBytes int64            `json:"installedBytes"`
+	RemainingBytes int64            `json:"remainingBytes"` // to download the rest
+}
+
+// ModelDiskUsage reports the size of each model in the catalog, for e.g.
+// "2 of 5 models installed, 2.0 GB used, 4.6 GB to complete".
+func (m *ModelService) ModelDiskUsage() ModelDiskReport {
+	dir := m.GetModelsDir()
+	catalog := modelCatalog()
+	report := ModelDiskReport{
+		Models:     make([]ModelDiskUsage, 0, len(catalog)),
+		TotalCount: len(catalog),
+	}
+	for _, def := range catalog {
+		usage := ModelDiskUsage{Name: def.Name}
+		if fi, err := os.Stat(filepath.Join(dir, def.FileName)); err == nil {
+			usage.Installed = true
+			usage.Bytes = fi.Size()
+			report.InstalledCount++
+			report.InstalledBytes += usage.Bytes
+		} else {
+			usage.Bytes = modelBytes(def)
+			report.RemainingBytes += usage.Bytes
+		}
+		report.Models = append(report.Models, usage)
+	}
+	return report
+}
+
+// sizeUnits maps unit suffixes to bytes. The catalog follows the usual
+// download-page convention where "MB" means MiB, so both forms are binary.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// parseSize parses human-readable sizes like "142 MB", "1.5GB", "3,1 GiB"
+// or "1,500 MB".
+func parseSize(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != ','
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	number := s[:i]
+	if c := strings.LastIndex(number, ","); c >= 0 {
+		// "1,500" groups thousands, "3,1" is a decimal comma
+		if strings.Contains(number, ".") || len(number)-c-1 == 3 {
+			number = strings.ReplaceAll(number, ",", "")
+		} else {
+			number = strings.Replace(number, ",", ".", 1)
+		}
+	}
+	unit := strings.TrimSpace(s[i:])
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit in %q", s)
+	}
+	return int64(value * float64(mult)), nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"os"
+)
+
+// Stereo downmix modes for imported WAVs
+const (
+	downmixAuto    = "auto"    // use the active channel if the other is silent, else average
+	downmixAverage = "average" // (L+R)/2, as whisper does itself
+	downmixLeft    = "left"
+	downmixRight   = "right"
+)
+
+// silentChannelRatio is how much weaker, in energy, a channel must be than
+// the other to count as silent in auto mode (-30dB).
+const silentChannelRatio = 1e-3
+
+// SetStereoDownmix chooses how stereo WAVs are mixed to mono before
+// transcription: "auto" (the default), "average", "left" or "right". Auto
+// avoids halving the signal of mono sources recorded to one channel.
+func (t *TranscribeService) SetStereoDownmix(mode string) error {
+	switch mode {
+	case downmixAuto, downmixAverage, downmixLeft, downmixRight:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.cfg.stereoDownmix = mode
+		return nil
+	}
+	return fmt.Errorf("unknown stereo downmix mode: %s", mode)
+}
+
+// downmixInput writes a mono copy of a stereo WAV when the downmix mode
+// calls for something other than whisper's own averaging. It returns "" when
+// the file can be used as is; otherwise the caller must remove the copy.
+func (c transcribeConfig) downmixInput(wavPath string) (string, error) {
+	f, err := os.Open(wavPath)
+	if err != nil {
+		return "", err
+	}
+	info, err := readWAVHeader(f)
+	f.Close()
+	if err != nil || info.numChannels != 2 {
+		return "", err
+	}
+
+	mode := c.stereoDownmix
+	if mode == "" {
+		mode = downmixAuto
+	}
+	if mode == downmixAverage {
+		return "", nil
+	}
+
+	samples, info, err := readWAV(wavPath)
+	if err != nil {
+		return "", err
+	}
+	if mode == downmixAuto {
+		if mode = activeChannel(samples); mode == downmixAverage {
+			return "", nil
+		}
+	}
+
+	ch := 0
+	if mode == downmixRight {
+		ch = 1
+	}
+	mono := make([]int16, len(samples)/2)
+	for i := range mono {
+		mono[i] = samples[i*2+ch]
+	}
+
+	tmp, err := os.CreateTemp("", "meeting_mono_*.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	monoPath := tmp.Name()
+	tmp.Close()
+	if err := writePCMWAV(monoPath, mono, info.sampleRate, 1); err != nil {
+		os.Remove(monoPath)
+		return "", fmt.Errorf("failed to write mono WAV: %w", err)
+	}
+	return monoPath, nil
+}
+
+// activeChannel compares the energy of interleaved stereo samples and
+// returns downmixLeft or downmixRight if only that channel carries signal,
+// or downmixAverage if both (or neither) do.
+func activeChannel(samples []int16) string {
+	var left, right float64
+	for i := 0; i+1 < len(samples); i += 2 {
+		l, r := float64(samples[i]), float64(samples[i+1])
+		left += l * l
+		right += r * r
+	}
+	switch {
+	case right < left*silentChannelRatio:
+		return downmixLeft
+	case left < right*silentChannelRatio:
+		return downmixRight
+	}
+	return downmixAverage
+}
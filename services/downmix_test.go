@@ -0,0 +1,40 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+// stereoTone interleaves a 440Hz tone at the given amplitudes.
+func stereoTone(leftAmp, rightAmp float64) []int16 {
+	const frames = 4800
+	out := make([]int16, 0, frames*2)
+	for i := range frames {
+		v := math.Sin(2 * math.Pi * 440 * float64(i) / 48000)
+		out = append(out, int16(leftAmp*v), int16(rightAmp*v))
+	}
+	return out
+}
+
+func TestActiveChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []int16
+		want    string
+	}{
+		{"both channels", stereoTone(10000, 8000), downmixAverage},
+		{"silent right", stereoTone(10000, 0), downmixLeft},
+		{"silent left", stereoTone(0, 10000), downmixRight},
+		{"right just noise", stereoTone(10000, 3), downmixLeft},
+		{"both silent", stereoTone(0, 0), downmixAverage},
+		{"empty", nil, downmixAverage},
+		{"odd length ignores the partial frame", append(stereoTone(0, 10000), 10000), downmixRight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := activeChannel(tt.samples); got != tt.want {
+				t.Errorf("activeChannel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package services
+
+import "math"
+
+const (
+	highPassCutoff  = 80.0  // Hz; removes rumble below the voice range
+	normalizeTarget = 0.9   // fraction of full scale the peak is raised to
+	normalizeMaxDb  = 20.0  // cap on normalization gain so noise isn't blown up
+	int16FullScale  = 32767 // largest positive int16 sample
+
+	telephoneLow  = 300.0  // Hz; lower edge of the telephone band
+	telephoneHigh = 3400.0 // Hz; upper edge of the telephone band
+
+	// Bounds for SetResampleFilterTaps
+	minResampleTaps = 7
+	maxResampleTaps = 511
+	sincPhases      = 512 // kernel table entries per input sample
+)
+
+// quantizer converts a processed sample back to int16; see ditherer.
+type quantizer func(float64) int16
+
+// resample converts mono samples from fromSR to toSR using simple linear
+// interpolation. Interpolated values are truncated unless q is given.
+func resample(samples []int16, fromSR, toSR float64, q quantizer) []int16 {
+	if fromSR == toSR {
+		return samples
+	}
+
+	ratio := fromSR / toSR
+	outLen := int(float64(len(samples)) / ratio)
+	out := make([]int16, outLen)
+	if q == nil {
+		q = func(v float64) int16 { return int16(v) }
+	}
+
+	for i := range out {
+		srcPos := float64(i) * ratio
+		idx := int(srcPos)
+		frac := srcPos - float64(idx)
+
+		if idx+1 < len(samples) {
+			out[i] = q(float64(samples[idx])*(1-frac) + float64(samples[idx+1])*frac)
+		} else if idx < len(samples) {
+			out[i] = samples[idx]
+		}
+	}
+
+	return out
+}
+
+// resampleTaps resamples with a windowed-sinc filter of the given length, or
+// by linear interpolation when taps is 0. q, if set, quantizes the output.
+func resampleTaps(samples []int16, fromSR, toSR float64, taps int, q quantizer) []int16 {
+	if taps == 0 {
+		return resample(samples, fromSR, toSR, q)
+	}
+	return resampleSinc(samples, fromSR, toSR, taps, q)
+}
+
+// resampleSinc converts mono samples from fromSR to toSR with a
+// Blackman-windowed sinc filter spanning taps input samples. The cutoff sits
+// just below the lower of the two Nyquist frequencies, so downsampling
+// doesn't alias. Output is rounded unless q is given.
+func resampleSinc(samples []int16, fromSR, toSR float64, taps int, q quantizer) []int16 {
+	if fromSR == toSR {
+		return samples
+	}
+
+	ratio := fromSR / toSR
+	outLen := int(float64(len(samples)) / ratio)
+	out := make([]int16, outLen)
+	// Cutoff as a fraction of the input rate, with a little room for the
+	// transition band
+	cutoff := 0.5 * math.Min(1, 1/ratio) * 0.95
+	half := float64(taps-1) / 2
+	if q == nil {
+		q = clampInt16
+	}
+
+	// Tabulate the kernel at sub-sample resolution rather than calling
+	// sin and cos per tap
+	kernel := make([]float64, int(2*half*sincPhases)+2)
+	for j := range kernel {
+		x := float64(j)/sincPhases - half
+		kernel[j] = 2 * cutoff * sinc(2*cutoff*x) * blackman(x/half)
+	}
+
+	for i := range out {
+		center := float64(i) * ratio
+		first := int(math.Ceil(center - half))
+		last := int(math.Floor(center + half))
+		sum, weights := 0.0, 0.0
+		for k := max(first, 0); k <= min(last, len(samples)-1); k++ {
+			w := kernel[int((float64(k)-center+half)*sincPhases+0.5)]
+			sum += w * float64(samples[k])
+			weights += w
+		}
+		// Dividing by the weights keeps unity gain at the edges
+		if weights != 0 {
+			sum /= weights
+		}
+		out[i] = q(sum)
+	}
+	return out
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window over x in [-1, 1].
+func blackman(x float64) float64 {
+	if x < -1 || x > 1 {
+		return 0
+	}
+	p := math.Pi * (x + 1)
+	return 0.42 - 0.5*math.Cos(p) + 0.08*math.Cos(2*p)
+}
+
+// resampleInterleaved resamples each channel of interleaved samples
+// separately; see resampleTaps.
+func resampleInterleaved(samples []int16, numChannels int, fromSR, toSR float64, taps int, q quantizer) []int16 {
+	if numChannels <= 1 || fromSR == toSR {
+		return resampleTaps(samples, fromSR, toSR, taps, q)
+	}
+
+	chans := make([][]int16, numChannels)
+	for c := range chans {
+		ch := make([]int16, 0, len(samples)/numChannels)
+		for i := c; i < len(samples); i += numChannels {
+			ch = append(ch, samples[i])
+		}
+		chans[c] = resampleTaps(ch, fromSR, toSR, taps, q)
+	}
+
+	out := make([]int16, len(chans[0])*numChannels)
+	for i := range chans[0] {
+		for c := range chans {
+			if i < len(chans[c]) {
+				out[i*numChannels+c] = chans[c][i]
+			}
+		}
+	}
+	return out
+}
+
+// mixToMono averages interleaved channels into a single channel.
+func mixToMono(samples []int16, numChannels int) []int16 {
+	if numChannels <= 1 {
+		return samples
+	}
+
+	out := make([]int16, len(samples)/numChannels)
+	for i := range out {
+		sum := 0
+		for c := 0; c < numChannels; c++ {
+			sum += int(samples[i*numChannels+c])
+		}
+		out[i] = int16(sum / numChannels)
+	}
+	return out
+}
+
+// highPassFilter applies a first-order high-pass filter, returning a new slice.
+func highPassFilter(samples []int16, sampleRate, cutoff float64) []int16 {
+	out := make([]int16, len(samples))
+	if len(samples) == 0 {
+		return out
+	}
+
+	rc := 1 / (2 * math.Pi * cutoff)
+	dt := 1 / sampleRate
+	alpha := rc / (rc + dt)
+
+	prevIn := float64(samples[0])
+	prevOut := 0.0
+	for i, s := range samples {
+		x := float64(s)
+		y := alpha * (prevOut + x - prevIn)
+		out[i] = clampInt16(y)
+		prevIn, prevOut = x, y
+	}
+	return out
+}
+
+// bandPassFilter keeps frequencies between low and high using cascaded
+// Butterworth biquads (4th order on each side), returning a new slice.
+func bandPassFilter(samples []int16, sampleRate, low, high float64) []int16 {
+	x := make([]float64, len(samples))
+	for i, s := range samples {
+		x[i] = float64(s)
+	}
+
+	stages := []biquad{newHighPassBiquad(sampleRate, low), newHighPassBiquad(sampleRate, low)}
+	if high < sampleRate/2 {
+		stages = append(stages, newLowPassBiquad(sampleRate, high), newLowPassBiquad(sampleRate, high))
+	}
+	for _, b := range stages {
+		b.process(x)
+	}
+
+	out := make([]int16, len(x))
+	for i, v := range x {
+		out[i] = clampInt16(v)
+	}
+	return out
+}
+
+// biquad holds normalized second-order filter coefficients.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// Butterworth Q for a single second-order section
+const butterworthQ = math.Sqrt2 / 2
+
+// newHighPassBiquad and newLowPassBiquad follow the RBJ audio EQ cookbook.
+func newHighPassBiquad(sampleRate, cutoff float64) biquad {
+	w := 2 * math.Pi * cutoff / sampleRate
+	alpha := math.Sin(w) / (2 * butterworthQ)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+func newLowPassBiquad(sampleRate, cutoff float64) biquad {
+	w := 2 * math.Pi * cutoff / sampleRate
+	alpha := math.Sin(w) / (2 * butterworthQ)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 - cos) / 2 / a0,
+		b1: (1 - cos) / a0,
+		b2: (1 - cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// process filters x in place.
+func (b biquad) process(x []float64) {
+	var x1, x2, y1, y2 float64
+	for i, x0 := range x {
+		y0 := b.b0*x0 + b.b1*x1 + b.b2*x2 - b.a1*y1 - b.a2*y2
+		x2, x1 = x1, x0
+		y2, y1 = y1, y0
+		x[i] = y0
+	}
+}
+
+// normalizePeak scales samples so the loudest one reaches normalizeTarget of
+// full scale, returning a new slice. Gain is capped at normalizeMaxDb.
+func normalizePeak(samples []int16) []int16 {
+	peak := 0
+	for _, s := range samples {
+		v := int(s)
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+
+	out := make([]int16, len(samples))
+	if peak == 0 {
+		return out
+	}
+
+	gain := normalizeTarget * int16FullScale / float64(peak)
+	if maxGain := math.Pow(10, normalizeMaxDb/20); gain > maxGain {
+		gain = maxGain
+	}
+	for i, s := range samples {
+		out[i] = clampInt16(float64(s) * gain)
+	}
+	return out
+}
+
+// applyGain scales samples by db decibels, clamping instead of wrapping
+// around when the result would clip. Returns a new slice.
+func applyGain(samples []int16, db float64) []int16 {
+	gain := math.Pow(10, db/20)
+	out := make([]int16, len(samples))
+	for i, s := range samples {
+		out[i] = clampInt16(float64(s) * gain)
+	}
+	return out
+}
+
+// rms returns the root-mean-square level of samples.
+func rms(samples []int16) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, s := range samples {
+		v := float64(s)
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func clampInt16(v float64) int16 {
+	if v > int16FullScale {
+		return int16FullScale
+	}
+	if v < -int16FullScale-1 {
+		return -int16FullScale - 1
+	}
+	return int16(math.Round(v))
+}
+
+// ditherer adds triangular-PDF dither of ±1 LSB before rounding, which
+// decorrelates quantization error from the signal. It uses a xorshift
+// generator, which is plenty random for this and cheap per sample.
+type ditherer struct {
+	state uint64
+}
+
+func newDitherer() *ditherer {
+	return &ditherer{state: 0x9e3779b97f4a7c15}
+}
+
+// uniform returns a pseudo-random value in [0, 1).
+func (d *ditherer) uniform() float64 {
+	d.state ^= d.state << 13
+	d.state ^= d.state >> 7
+	d.state ^= d.state << 17
+	return float64(d.state>>11) / (1 << 53)
+}
+
+// quantize rounds v to int16 after adding dither, the difference of two
+// uniform values.
+func (d *ditherer) quantize(v float64) int16 {
+	return clampInt16(v + d.uniform() - d.uniform())
+}
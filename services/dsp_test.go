@@ -0,0 +1,92 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDithererStaysInRange(t *testing.T) {
+	d := newDitherer()
+	for _, v := range []float64{int16FullScale, int16FullScale + 0.9, -int16FullScale - 1, -int16FullScale - 1.9, 1e9, -1e9} {
+		for range 1000 {
+			q := d.quantize(v)
+			if v > 0 && q < int16FullScale-1 {
+				t.Fatalf("quantize(%v) = %d, want near full scale", v, q)
+			}
+			if v < 0 && q > -int16FullScale {
+				t.Fatalf("quantize(%v) = %d, want near negative full scale", v, q)
+			}
+		}
+	}
+}
+
+func TestDithererDecorrelatesError(t *testing.T) {
+	// Plain rounding turns a constant 0.25 into 0 every time, an error fully
+	// determined by the signal. Dithered, the output averages to the input.
+	const n = 100000
+	d := newDitherer()
+	for _, v := range []float64{0.25, -0.25, 100.5, -3.75} {
+		var sum float64
+		for range n {
+			q := float64(d.quantize(v))
+			if math.Abs(q-v) > 2 {
+				t.Fatalf("quantize(%v) = %v, more than 2 LSB off", v, q)
+			}
+			sum += q
+		}
+		if mean := sum / n; math.Abs(mean-v) > 0.01 {
+			t.Errorf("mean of quantize(%v) = %v, want %v", v, mean, v)
+		}
+	}
+}
+
+func TestDithererErrorUncorrelatedWithSignal(t *testing.T) {
+	// A slow ramp through fractional values: the error of plain rounding
+	// tracks the fractional part, the dithered error shouldn't.
+	const n = 100000
+	d := newDitherer()
+	var sxy, sx, sy, sxx, syy float64
+	for i := range n {
+		v := float64(i%1000) / 100
+		e := float64(d.quantize(v)) - v
+		frac := v - math.Round(v)
+		sx += frac
+		sy += e
+		sxy += frac * e
+		sxx += frac * frac
+		syy += e * e
+	}
+	cov := sxy/n - sx/n*sy/n
+	r := cov / math.Sqrt((sxx/n-sx/n*sx/n)*(syy/n-sy/n*sy/n))
+	if math.Abs(r) > 0.05 {
+		t.Errorf("dither error correlates with signal: r = %.3f", r)
+	}
+}
+
+func TestBandPassFilterAttenuatesOutOfBand(t *testing.T) {
+	const sampleRate = 16000
+	tone := func(freq float64) []int16 {
+		out := make([]int16, sampleRate/2)
+		for i := range out {
+			out[i] = int16(10000 * math.Sin(2*math.Pi*freq*float64(i)/sampleRate))
+		}
+		return out
+	}
+	// Relative level after the filter settles; skip its start-up transient
+	gain := func(freq float64) float64 {
+		in := tone(freq)
+		out := bandPassFilter(in, sampleRate, telephoneLow, telephoneHigh)
+		return rms(out[len(out)/2:]) / rms(in[len(in)/2:])
+	}
+
+	for _, freq := range []float64{600, 1000, 2000} {
+		if g := gain(freq); g < 0.9 || g > 1.1 {
+			t.Errorf("%gHz in band: gain %.3f, want about 1", freq, g)
+		}
+	}
+	for _, freq := range []float64{50, 100, 7000} {
+		if g := gain(freq); g > 0.1 {
+			t.Errorf("%gHz out of band: gain %.3f, want below 0.1 (-20dB)", freq, g)
+		}
+	}
+}
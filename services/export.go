@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ExportAudio writes the last recording to destPath at the archive sample
+// rate (the device's native rate unless SetArchiveSampleRate says
+// otherwise). Supported formats are "wav" and "flac"; FLAC is lossless and
+// requires ffmpeg. The 16kHz transcription WAV is unaffected. Resampling
+// runs after a.mu is released, so it can't hold up a new recording.
+func (a *AudioService) ExportAudio(destPath, format string) error {
+	a.mu.Lock()
+	if a.state != stateIdle {
+		a.mu.Unlock()
+		return fmt.Errorf("cannot export while %s", a.state)
+	}
+	// Samples are replaced, never modified, so sharing them is safe
+	samples := a.samples[:len(a.samples):len(a.samples)]
+	numChannels := a.numChannels
+	bits := max(a.archiveBits, bitDepth)
+	nativeSR, archiveSR := a.nativeSR, a.archiveSR
+	taps, dither := a.resampleTaps, !a.noDither
+	a.mu.Unlock()
+
+	sr := int(nativeSR)
+	if archiveSR > 0 && archiveSR != sr {
+		var q quantizer
+		if dither {
+			q = newDitherer().quantize
+		}
+		samples = resampleInterleaved(samples, numChannels, nativeSR, float64(archiveSR), taps, q)
+		sr = archiveSR
+	}
+
+	if len(samples) == 0 {
+		return fmt.Errorf("no recording to export")
+	}
+
+	switch strings.ToLower(format) {
+	case "wav":
+		return writePCMWAVBits(destPath, samples, sr, numChannels, bits)
+	case "flac":
+		return exportFLAC(destPath, samples, sr, numChannels, bits)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// SetKeepNativeRecording also saves each recording as a WAV at the device's
+// native sample rate and channel count, next to the transcription audio, so
+// it can be re-processed at full quality later. Its path is the recording's
+// NativeWavPath.
+func (a *AudioService) SetKeepNativeRecording(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keepNative = enabled
+}
+
+// SetDither toggles triangular dither wherever audio is reduced to int16:
+// when ExportAudio resamples to the archive rate, and when a float32-only
+// input device is converted as it records (from the next recording). It
+// turns rounding distortion into a low, even noise floor, and is on by
+// default.
+func (a *AudioService) SetDither(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.noDither = !enabled
+}
+
+// SetArchiveBitDepth sets the bit depth used by ExportAudio (16 or 24).
+// The transcription WAV is always 16-bit.
+func (a *AudioService) SetArchiveBitDepth(bits int) error {
+	if bits != 16 && bits != 24 {
+		return fmt.Errorf("archive bit depth must be 16 or 24, got %d", bits)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.archiveBits = bits
+	return nil
+}
+
+func exportFLAC(destPath string, samples []int16, sampleRate, numChannels, bits int) error {
+	ffmpeg := findExecutable("ffmpeg")
+	if ffmpeg == "" {
+		return fmt.Errorf("ffmpeg is required for FLAC export. Please install it with: brew install ffmpeg")
+	}
+
+	tmp, err := os.CreateTemp("", "meeting_export_*.wav")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	if err := writePCMWAVBits(tmpPath, samples, sampleRate, numChannels, bits); err != nil {
+		return fmt.Errorf("failed to write temp WAV: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	cmd := exec.Command(ffmpeg, "-y", "-loglevel", "error", "-i", tmpPath, "-c:a", "flac", destPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"os"
+)
+
+const defaultModelFileMode os.FileMode = 0644
+
+// SetModelFileMode sets the permissions of downloaded and imported model
+// files, e.g. 0600 to keep them private on a shared machine. The models
+// directory gets the same permissions plus search (x) wherever reading is
+// allowed, so 0644 gives 0755 and 0600 gives 0700. Both are applied with
+// chmod, so the result doesn't depend on the umask. The owner must keep
+// read and write access.
+func (m *ModelService) SetModelFileMode(mode os.FileMode) error {
+	if mode&^os.ModePerm != 0 || mode&0600 != 0600 {
+		return fmt.Errorf("model file mode must be a permission mode that includes 0600, got %#o", mode)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fileMode = mode
+	return nil
+}
+
+// modelModes returns the permissions for model files and their directory.
+func (m *ModelService) modelModes() (file, dir os.FileMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	file = m.fileMode
+	if file == 0 {
+		file = defaultModelFileMode
+	}
+	// Directories need x to be entered wherever r is granted
+	return file, file | (file&0444)>>2
+}
+
+// applyModelModes chmods the models directory and the given files in it.
+func (m *ModelService) applyModelModes(dir string, files ...string) error {
+	fileMode, dirMode := m.modelModes()
+	if err := os.Chmod(dir, dirMode); err != nil {
+		return fmt.Errorf("failed to set directory permissions: %w", err)
+	}
+	for _, f := range files {
+		if err := os.Chmod(f, fileMode); err != nil {
+			return fmt.Errorf("failed to set file permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// openPart opens a partial model file with the model file permissions from
+// the start, so a private mode also covers the file while it's written.
+// flag is passed to os.OpenFile; the chmod covers files that already existed
+// and the umask.
+func (m *ModelService) openPart(path string, flag int) (*os.File, error) {
+	fileMode, _ := m.modelModes()
+	f, err := os.OpenFile(path, flag, fileMode)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Chmod(fileMode); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestOpenPartUsesModelFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix permission bits")
+	}
+
+	m := &ModelService{}
+	if err := m.SetModelFileMode(0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	fresh := filepath.Join(dir, "fresh.bin.part")
+	// Left by an earlier download made with the default mode
+	existing := filepath.Join(dir, "existing.bin.part")
+	if err := os.WriteFile(existing, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path string
+		flag int
+	}{
+		{fresh, os.O_RDWR | os.O_CREATE | os.O_TRUNC},
+		{existing, os.O_RDWR},
+	} {
+		f, err := m.openPart(tc.path, tc.flag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, err := f.Stat()
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fi.Mode().Perm(); got != 0o600 {
+			t.Errorf("%s opened with mode %#o, want 0600", filepath.Base(tc.path), got)
+		}
+	}
+}
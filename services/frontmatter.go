@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const frontMatterDelim = "---"
+
+// MeetingMeta is optional metadata saved as YAML front matter at the top of
+// a transcript, in the form Obsidian and Jekyll read.
+type MeetingMeta struct {
+	Title     string   `json:"title"`
+	Attendees []string `json:"attendees"`
+	Tags      []string `json:"tags"`
+	Notes     string   `json:"notes"`
+}
+
+func (m MeetingMeta) isEmpty() bool {
+	return m.Title == "" && len(m.Attendees) == 0 && len(m.Tags) == 0 && m.Notes == ""
+}
+
+// cleanMeetingMeta trims meta and drops empty list entries. Only Notes may
+// span several lines.
+func cleanMeetingMeta(meta MeetingMeta) (MeetingMeta, error) {
+	clean := MeetingMeta{
+		Title: strings.TrimSpace(meta.Title),
+		Notes: strings.TrimSpace(meta.Notes),
+	}
+	if hasControlChars(clean.Title) {
+		return MeetingMeta{}, fmt.Errorf("title cannot contain line breaks or control characters")
+	}
+
+	var err error
+	if clean.Attendees, err = cleanMetaList("attendee", meta.Attendees); err != nil {
+		return MeetingMeta{}, err
+	}
+	if clean.Tags, err = cleanMetaList("tag", meta.Tags); err != nil {
+		return MeetingMeta{}, err
+	}
+	return clean, nil
+}
+
+func cleanMetaList(kind string, values []string) ([]string, error) {
+	var result []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if hasControlChars(v) {
+			return nil, fmt.Errorf("%s %q cannot contain line breaks or control characters", kind, v)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func hasControlChars(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
+
+// renderFrontMatter returns the YAML front matter block for meta, or "" if
+// meta is empty. Every value is written as a double-quoted scalar, so
+// colons, "#" and leading dashes in names can't change the structure.
+func renderFrontMatter(meta MeetingMeta, date string) string {
+	if meta.isEmpty() {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(frontMatterDelim + "\n")
+	writeScalar := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", key, yamlQuote(value))
+		}
+	}
+	writeList := func(key string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		sb.WriteString(key + ":\n")
+		for _, v := range values {
+			fmt.Fprintf(&sb, "  - %s\n", yamlQuote(v))
+		}
+	}
+
+	writeScalar("title", meta.Title)
+	writeScalar("date", date)
+	writeList("attendees", meta.Attendees)
+	writeList("tags", meta.Tags)
+	writeScalar("notes", meta.Notes)
+	sb.WriteString(frontMatterDelim + "\n\n")
+	return sb.String()
+}
+
+// yamlQuote returns s as a YAML double-quoted scalar. Go's escapes for
+// quoted strings are a subset of YAML's, so strconv does the work.
+func yamlQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+// parseFrontMatter reads the front matter at the start of content. It
+// accepts what renderFrontMatter writes plus the common hand-edited forms:
+// unquoted or single-quoted scalars and inline [a, b] lists. ok is false
+// when content has no front matter.
+func parseFrontMatter(content string) (meta MeetingMeta, date string, ok bool) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	rest, found := strings.CutPrefix(content, frontMatterDelim+"\n")
+	if !found {
+		return MeetingMeta{}, "", false
+	}
+	block, _, found := strings.Cut(rest, "\n"+frontMatterDelim+"\n")
+	if !found {
+		return MeetingMeta{}, "", false
+	}
+
+	var listKey string
+	for _, line := range strings.Split(block, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if item, isItem := strings.CutPrefix(trimmed, "- "); isItem && listKey != "" {
+			appendMetaList(&meta, listKey, yamlUnquote(item))
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		listKey = ""
+
+		switch key {
+		case "title":
+			meta.Title = yamlUnquote(value)
+		case "date":
+			date = yamlUnquote(value)
+		case "notes":
+			meta.Notes = yamlUnquote(value)
+		case "attendees", "tags":
+			if value == "" {
+				listKey = key
+				continue
+			}
+			for _, item := range yamlInlineList(value) {
+				appendMetaList(&meta, key, item)
+			}
+		}
+	}
+	return meta, date, true
+}
+
+func appendMetaList(meta *MeetingMeta, key, value string) {
+	if value == "" {
+		return
+	}
+	switch key {
+	case "attendees":
+		meta.Attendees = append(meta.Attendees, value)
+	case "tags":
+		meta.Tags = append(meta.Tags, value)
+	}
+}
+
+func yamlUnquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+			return s[1 : len(s)-1]
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+		}
+	}
+	return s
+}
+
+// yamlInlineList splits "[a, "b, c"]" or a bare scalar into its items.
+func yamlInlineList(s string) []string {
+	inner, ok := strings.CutPrefix(s, "[")
+	if !ok {
+		return []string{yamlUnquote(s)}
+	}
+	inner = strings.TrimSuffix(inner, "]")
+
+	var items []string
+	var cur strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range inner {
+		switch {
+		case quote != 0:
+			if r == quote && !escaped {
+				quote = 0
+			}
+			escaped = quote == '"' && r == '\\' && !escaped
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			cur.WriteRune(r)
+		case r == ',':
+			items = append(items, yamlUnquote(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	items = append(items, yamlUnquote(cur.String()))
+	return items
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetGrammar constrains decoding with a GBNF grammar file, for meetings with
+// a fixed vocabulary or command set. This is an advanced option: a grammar
+// that doesn't match what is said makes whisper produce nonsense, so most
+// users should leave it unset. It needs a whisper-cli build with --grammar;
+// an empty path turns it off.
+func (t *TranscribeService) SetGrammar(path string) error {
+	if path == "" {
+		t.mu.Lock()
+		t.cfg.grammarPath = ""
+		t.mu.Unlock()
+		return nil
+	}
+	if !t.supportsFlag("--grammar") {
+		return fmt.Errorf("the installed whisper binary doesn't support grammars; update whisper-cpp to use this")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read grammar file: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot read grammar file: %w", err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("grammar path is a directory: %s", path)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.grammarPath = path
+	return nil
+}
+
+// SetGrammarRule names the grammar's top-level rule, passed as
+// --grammar-rule. Empty uses whisper's default ("root").
+func (t *TranscribeService) SetGrammarRule(rule string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.grammarRule = rule
+}
+
+// grammarArgs returns the whisper flags for the grammar in cfg.
+func (t *TranscribeService) grammarArgs(cfg transcribeConfig) ([]string, error) {
+	if cfg.grammarPath == "" {
+		return nil, nil
+	}
+	if !t.supportsFlag("--grammar") {
+		return nil, fmt.Errorf("a grammar is set but the installed whisper binary doesn't support it; clear it with SetGrammar(\"\") or update whisper-cpp")
+	}
+	if _, err := os.Stat(cfg.grammarPath); err != nil {
+		return nil, fmt.Errorf("cannot read grammar file: %w", err)
+	}
+	args := []string{"--grammar", cfg.grammarPath}
+	if cfg.grammarRule != "" {
+		args = append(args, "--grammar-rule", cfg.grammarRule)
+	}
+	return args, nil
+}
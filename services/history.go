@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// versionSuffix matches the "_v2" style suffix of re-transcribed markdown.
+var versionSuffix = regexp.MustCompile(`_v\d+$`)
+
+// RetranscribeFromHistory re-runs transcription on the WAV saved next to a
+// markdown transcript, using the current model and settings, and saves it
+// like TranscribeToFile does, sidecar and plain text included. For one part
+// of a split transcript the whole recording is transcribed and every part
+// is written again. Returns the path of the (first) written markdown, which
+// replaces mdPath or is a new versioned file depending on
+// SetRetranscribeOverwrite. It fails for recordings whose audio was removed
+// by SetDeleteWAVAfterTranscription.
+func (t *TranscribeService) RetranscribeFromHistory(mdPath string) (string, error) {
+	wavPath, err := savedAudioPath(transcriptBase(strings.TrimSuffix(mdPath, filepath.Ext(mdPath))))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("no saved recording found for %s; SetDeleteWAVAfterTranscription may have removed its audio", filepath.Base(mdPath))
+		}
+		return "", fmt.Errorf("cannot read saved recording: %w", err)
+	}
+
+	cfg := t.config()
+	result, err := t.transcribe(cfg, wavPath)
+	if err != nil {
+		return "", err
+	}
+	return saveRetranscription(cfg, mdPath, wavPath, result)
+}
+
+// saveRetranscription writes result, the new transcription of the saved
+// recording wavPath, over mdPath or as its next version.
+func saveRetranscription(cfg transcribeConfig, mdPath, wavPath string, result transcription) (string, error) {
+	// Carry the meeting metadata over to the new version
+	var meta MeetingMeta
+	if data, err := os.ReadFile(mdPath); err == nil {
+		meta, _, _ = parseFrontMatter(string(data))
+	}
+
+	// The parts are written again together, under the name they share
+	stem := strings.TrimSuffix(mdPath, filepath.Ext(mdPath))
+	outBase := partSuffix.ReplaceAllString(stem, "")
+	if !cfg.retranscribeOverwrite {
+		outBase = nextVersionBase(transcriptBase(stem))
+	}
+
+	recordingID := recordings.idForPath(wavPath)
+	if recordingID == "" {
+		recordingID = recordings.idForPath(mdPath)
+	}
+	audioHash, _ := audioFileHash(wavPath)
+	res, err := cfg.writeTranscriptOutputs(transcriptOutput{
+		dir:            filepath.Dir(outBase),
+		base:           filepath.Base(outBase),
+		recordingID:    recordingID,
+		audioHash:      audioHash,
+		savedAudioPath: wavPath,
+		date:           time.Now().Format("2006-01-02 15:04:05"),
+	}, meta, result)
+	if err != nil {
+		return "", err
+	}
+	cfg.removeStaleOutputs(outBase, res)
+	cfg.recordTranscription(recordingID, res, wavPath)
+	if cfg.noMarkdown {
+		return res.TextPaths[0], nil
+	}
+	return res.MarkdownPaths[0], nil
+}
+
+// TranscriptionEntry describes a saved transcript for the history list.
+type TranscriptionEntry struct {
+	Path      string   `json:"path"`
+	Date      string   `json:"date"`
+	Title     string   `json:"title"`
+	Attendees []string `json:"attendees"`
+	Tags      []string `json:"tags"`
+	HasAudio  bool     `json:"hasAudio"`
+}
+
+// markdownDateLine matches the date line written by the markdown template.
+var markdownDateLine = regexp.MustCompile(`(?m)^\*\*Date:\*\* (.+)$`)
+
+// ListTranscriptions returns the saved transcripts, newest first, with the
+// metadata from their front matter.
+func (t *TranscribeService) ListTranscriptions() ([]TranscriptionEntry, error) {
+	saveDir, err := transcriptionsDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(saveDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcriptions directory: %w", err)
+	}
+
+	type listed struct {
+		entry   TranscriptionEntry
+		modTime time.Time
+	}
+	var found []listed
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".md" {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(saveDir, f.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		meta, date, _ := parseFrontMatter(string(data))
+		if date == "" {
+			if m := markdownDateLine.FindSubmatch(data); m != nil {
+				date = strings.TrimSpace(string(m[1]))
+			} else {
+				date = info.ModTime().Format("2006-01-02 15:04:05")
+			}
+		}
+
+		_, audioErr := savedAudioPath(transcriptBase(strings.TrimSuffix(path, ".md")))
+
+		found = append(found, listed{
+			entry: TranscriptionEntry{
+				Path:      path,
+				Date:      date,
+				Title:     meta.Title,
+				Attendees: meta.Attendees,
+				Tags:      meta.Tags,
+				HasAudio:  audioErr == nil,
+			},
+			modTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.After(found[j].modTime) })
+	entries := make([]TranscriptionEntry, len(found))
+	for i, l := range found {
+		entries[i] = l.entry
+	}
+	return entries, nil
+}
+
+// transcriptBase strips the "_partN" and "_vN" suffixes from a transcript
+// path without extension, leaving the name its recording was saved under.
+// Split versions are named like "_v2_part1", older ones "_part1_v2".
+func transcriptBase(stem string) string {
+	stem = partSuffix.ReplaceAllString(stem, "")
+	stem = versionSuffix.ReplaceAllString(stem, "")
+	return partSuffix.ReplaceAllString(stem, "")
+}
+
+// savedAudioPath returns the recording saved next to a transcript, which is
+// base.wav or, with SetCompressedTemp, base.flac.
+func savedAudioPath(base string) (string, error) {
+	var firstErr error
+	for _, ext := range []string{".wav", ".flac"} {
+		_, err := os.Stat(base + ext)
+		if err == nil {
+			return base + ext, nil
+		}
+		if firstErr == nil || !errors.Is(err, os.ErrNotExist) {
+			firstErr = err
+		}
+	}
+	return "", firstErr
+}
+
+// SetRetranscribeOverwrite chooses whether RetranscribeFromHistory replaces
+// the existing markdown or writes a new "_vN" version alongside it.
+func (t *TranscribeService) SetRetranscribeOverwrite(overwrite bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.retranscribeOverwrite = overwrite
+}
+
+// nextVersionBase returns the first "<base>_vN" with no transcript files
+// yet, split or not.
+func nextVersionBase(base string) string {
+	for v := 2; ; v++ {
+		p := fmt.Sprintf("%s_v%d", base, v)
+		if !slices.ContainsFunc([]string{".md", "_part1.md", ".txt", "_part1.txt", transcriptSuffix}, func(suffix string) bool {
+			_, err := os.Stat(p + suffix)
+			return err == nil
+		}) {
+			return p
+		}
+	}
+}
+
+// removeStaleOutputs deletes the files under outBase, split or not, that an
+// earlier transcription left and res didn't replace, so a sidecar with the
+// old text can't later be re-rendered over the new markdown. Only kinds of
+// file that res was written as are touched.
+func (c transcribeConfig) removeStaleOutputs(outBase string, res TranscribeResult) {
+	suffixes := []string{transcriptSuffix}
+	if !c.noMarkdown {
+		suffixes = append(suffixes, ".md")
+	}
+	if c.plainOutput {
+		suffixes = append(suffixes, ".txt")
+	}
+	written := slices.Concat(res.MarkdownPaths, res.TextPaths, res.TranscriptPaths)
+	remove := func(base string) (found bool) {
+		for _, suffix := range suffixes {
+			p := base + suffix
+			if _, err := os.Stat(p); err != nil {
+				continue
+			}
+			found = true
+			if !slices.Contains(written, p) {
+				if err := os.Remove(p); err != nil {
+					log.Printf("failed to remove outdated %s: %v", filepath.Base(p), err)
+				}
+			}
+		}
+		return found
+	}
+	remove(outBase)
+	// Parts are numbered from 1 without gaps
+	for n := 1; ; n++ {
+		if !remove(fmt.Sprintf("%s_part%d", outBase, n)) {
+			break
+		}
+	}
+}
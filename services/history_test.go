@@ -0,0 +1,179 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeHistoryTranscript saves a transcript of text under base the way
+// saveTranscription does, with a sidecar and the audio next to it.
+func writeHistoryTranscript(t *testing.T, cfg transcribeConfig, base, text string) {
+	t.Helper()
+	writeHistorySegments(t, cfg, base, []Segment{{Start: 0, End: 2, Text: text}})
+}
+
+// writeHistorySegments is writeHistoryTranscript for a transcript of
+// several segments, which cfg may split.
+func writeHistorySegments(t *testing.T, cfg transcribeConfig, base string, segments []Segment) {
+	t.Helper()
+	if err := os.WriteFile(base+".wav", []byte("RIFF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := cfg.writeTranscriptOutputs(transcriptOutput{
+		dir:  filepath.Dir(base),
+		base: filepath.Base(base),
+		date: "2026-01-02 15:04:05",
+	}, MeetingMeta{Title: "Weekly sync"}, transcription{
+		Text:     joinSegmentText(segments),
+		Segments: segments,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetranscribeOverwriteThenRegenerate(t *testing.T) {
+	useTempHome(t)
+	base := filepath.Join(t.TempDir(), "2026-01-02_150405")
+	cfg := transcribeConfig{retranscribeOverwrite: true, onCollision: collisionOverwrite}
+	writeHistoryTranscript(t, cfg, base, "the old text")
+
+	result := transcription{Text: "the new text", Segments: []Segment{{Start: 0, End: 2, Text: "the new text"}}}
+	mdPath, err := saveRetranscription(cfg, base+".md", base+".wav", result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mdPath != base+".md" {
+		t.Errorf("saveRetranscription() = %s, want %s overwritten", mdPath, base+".md")
+	}
+
+	ts := &TranscribeService{cfg: cfg}
+	if mdPath, err = ts.RegenerateMarkdown(base + transcriptSuffix); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	if !strings.Contains(md, "the new text") || strings.Contains(md, "the old text") {
+		t.Errorf("regenerated markdown lost the re-transcription:\n%s", md)
+	}
+	if !strings.Contains(md, "Weekly sync") {
+		t.Errorf("regenerated markdown lost the meeting title:\n%s", md)
+	}
+}
+
+func TestRetranscribeWritesNewVersion(t *testing.T) {
+	useTempHome(t)
+	base := filepath.Join(t.TempDir(), "2026-01-02_150405")
+	cfg := transcribeConfig{plainOutput: true}
+	writeHistoryTranscript(t, cfg, base, "the old text")
+
+	result := transcription{Text: "the new text", Segments: []Segment{{Start: 0, End: 2, Text: "the new text"}}}
+	for _, want := range []string{base + "_v2.md", base + "_v3.md"} {
+		mdPath, err := saveRetranscription(cfg, base+".md", base+".wav", result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mdPath != want {
+			t.Errorf("saveRetranscription() = %s, want %s", mdPath, want)
+		}
+		stem := strings.TrimSuffix(want, ".md")
+		for _, p := range []string{stem + ".txt", stem + transcriptSuffix} {
+			if _, err := os.Stat(p); err != nil {
+				t.Errorf("%s not written: %v", filepath.Base(p), err)
+			}
+		}
+	}
+	if data, err := os.ReadFile(base + ".md"); err != nil || !strings.Contains(string(data), "the old text") {
+		t.Errorf("original markdown changed: %q, %v", data, err)
+	}
+}
+
+func TestRetranscribeOverwriteRemovesStaleSidecar(t *testing.T) {
+	useTempHome(t)
+	base := filepath.Join(t.TempDir(), "2026-01-02_150405")
+	cfg := transcribeConfig{retranscribeOverwrite: true}
+	writeHistoryTranscript(t, cfg, base, "the old text")
+
+	// No timed segments this time, so there's no new sidecar to replace it
+	if _, err := saveRetranscription(cfg, base+".md", base+".wav", transcription{Text: "the new text"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(base + transcriptSuffix); !os.IsNotExist(err) {
+		t.Errorf("sidecar with the old text was kept: %v", err)
+	}
+}
+
+func TestRetranscribePartRewritesEveryPart(t *testing.T) {
+	useTempHome(t)
+	base := filepath.Join(t.TempDir(), "2026-01-02_150405")
+	cfg := transcribeConfig{retranscribeOverwrite: true, split: SplitConfig{EveryMinutes: 1}}
+	writeHistorySegments(t, cfg, base, []Segment{
+		{Start: 0, End: 30, Text: "old one"},
+		{Start: 60, End: 90, Text: "old two"},
+		{Start: 120, End: 150, Text: "old three"},
+	})
+
+	// Picking the second part re-transcribes the whole recording, which
+	// now only has two parts
+	result := transcription{Text: "new one new two", Segments: []Segment{
+		{Start: 0, End: 30, Text: "new one"},
+		{Start: 60, End: 90, Text: "new two"},
+	}}
+	mdPath, err := saveRetranscription(cfg, base+"_part2.md", base+".wav", result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mdPath != base+"_part1.md" {
+		t.Errorf("saveRetranscription() = %s, want the first part", mdPath)
+	}
+	for _, p := range []struct{ suffix, want, other string }{
+		{"_part1", "new one", "new two"},
+		{"_part2", "new two", "new one"},
+	} {
+		data, err := os.ReadFile(base + p.suffix + ".md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if md := string(data); !strings.Contains(md, p.want) || strings.Contains(md, p.other) || strings.Contains(md, "old") {
+			t.Errorf("%s.md should hold only %q:\n%s", p.suffix, p.want, md)
+		}
+	}
+	for _, suffix := range []string{"_part3.md", "_part3" + transcriptSuffix, ".md"} {
+		if _, err := os.Stat(base + suffix); !os.IsNotExist(err) {
+			t.Errorf("%s left behind: %v", filepath.Base(base+suffix), err)
+		}
+	}
+
+	// A new version of a part is the whole recording too, and still finds
+	// its audio
+	cfg.retranscribeOverwrite = false
+	if mdPath, err = saveRetranscription(cfg, base+"_part2.md", base+".wav", result); err != nil {
+		t.Fatal(err)
+	}
+	if mdPath != base+"_v2_part1.md" {
+		t.Errorf("saveRetranscription() = %s, want %s", mdPath, base+"_v2_part1.md")
+	}
+	if _, err := savedAudioPath(transcriptBase(strings.TrimSuffix(mdPath, ".md"))); err != nil {
+		t.Errorf("no audio found for %s: %v", filepath.Base(mdPath), err)
+	}
+}
+
+func TestTranscriptBase(t *testing.T) {
+	for stem, want := range map[string]string{
+		"2026-01-02_150405":          "2026-01-02_150405",
+		"2026-01-02_150405_part2":    "2026-01-02_150405",
+		"2026-01-02_150405_v3":       "2026-01-02_150405",
+		"2026-01-02_150405_v3_part2": "2026-01-02_150405",
+		"2026-01-02_150405_part2_v3": "2026-01-02_150405",
+		"2026-01-02_150405-1_part10": "2026-01-02_150405-1",
+	} {
+		if got := transcriptBase(stem); got != want {
+			t.Errorf("transcriptBase(%q) = %q, want %q", stem, got, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ExportTranscript writes a saved transcript (a .transcript.json file) to
+// destPath in another format. The only format so far is "labels", an
+// Audacity label track that can be imported alongside the recording via
+// File > Import > Labels. It needs segment timings, so transcripts saved
+// without them can't be exported.
+func (t *TranscribeService) ExportTranscript(transcriptPath, destPath, format string) error {
+	tf, err := readTranscriptFile(transcriptPath)
+	if err != nil {
+		return err
+	}
+
+	var data string
+	switch strings.ToLower(format) {
+	case "labels":
+		if len(tf.Segments) == 0 {
+			return fmt.Errorf("transcript has no segment timings; label tracks need them")
+		}
+		data = audacityLabels(tf.Segments, tf.SpeakerLabels)
+	default:
+		return fmt.Errorf("unsupported transcript export format: %s", format)
+	}
+
+	if err := os.WriteFile(destPath, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", destPath, err)
+	}
+	return nil
+}
+
+// audacityLabels formats segments as Audacity label lines,
+// "start<TAB>end<TAB>label" with times in seconds. Tabs and line breaks in
+// the label are replaced by spaces since they would end it early.
+// With speaker labels each label starts with the speaker's name.
+func audacityLabels(segments []Segment, speakers bool) string {
+	var sb strings.Builder
+	for _, s := range segments {
+		label := s.Text
+		if speakers && s.Speaker != "" {
+			label = s.Speaker + ": " + label
+		}
+		label = strings.Join(strings.Fields(label), " ")
+		fmt.Fprintf(&sb, "%.6f\t%.6f\t%s\n", s.Start, max(s.End, s.Start), label)
+	}
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package services
+
+import "testing"
+
+func TestAudacityLabels(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 1.5, Text: " Hello\tthere ", Speaker: "Alice"},
+		{Start: 1.5, End: 3.25, Text: "two\r\nlines", Speaker: "Bob\tSmith"},
+		{Start: 4, End: 3.9, Text: "no speaker"},
+	}
+
+	tests := []struct {
+		name     string
+		speakers bool
+		want     string
+	}{
+		{
+			name:     "speakers",
+			speakers: true,
+			want: "0.000000\t1.500000\tAlice: Hello there\n" +
+				"1.500000\t3.250000\tBob Smith: two lines\n" +
+				"4.000000\t4.000000\tno speaker\n",
+		},
+		{
+			name:     "no speakers",
+			speakers: false,
+			want: "0.000000\t1.500000\tHello there\n" +
+				"1.500000\t3.250000\ttwo lines\n" +
+				"4.000000\t4.000000\tno speaker\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := audacityLabels(segments, tt.speakers); got != tt.want {
+				t.Errorf("audacityLabels() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
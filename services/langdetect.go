@@ -0,0 +1,82 @@
+package services
+
+import (
+	"regexp"
+	"slices"
+)
+
+// detectedLanguagePattern matches whisper's log line for language "auto",
+// e.g. "whisper_full_with_state: auto-detected language: ja (p = 0.97)".
+var detectedLanguagePattern = regexp.MustCompile(`auto-detected language: (\w+)`)
+
+// detectedLanguage returns the language whisper reported detecting in its
+// output, or "" if it didn't.
+func detectedLanguage(output string) string {
+	if m := detectedLanguagePattern.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// GetSuggestedLanguage returns the language detected by the first
+// transcription in "auto" mode, so the UI can offer to make it the default
+// instead of detecting it every time. It's never applied automatically, and
+// is cleared when a specific language is set. Returns "" if there's none.
+func (t *TranscribeService) GetSuggestedLanguage() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.suggestedLanguage
+}
+
+// noteDetectedLanguage records lang as the suggestion if it's the first
+// detection since auto mode was chosen. Callers must hold t.mu.
+func (t *TranscribeService) noteDetectedLanguage(lang string) {
+	if t.cfg.language == "auto" && t.suggestedLanguage == "" && lang != "" && lang != "auto" {
+		t.suggestedLanguage = lang
+	}
+}
+
+// DetectedLanguages returns the languages whisper detected in the last
+// transcription in "auto" mode, in order of first appearance. Chunked
+// transcriptions detect per chunk, so a code-switched meeting can yield
+// several. Detection runs once per whisper run and is approximate: a chunk
+// mixing languages is tagged with whichever dominates.
+func (t *TranscribeService) DetectedLanguages() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.detectedLanguages)
+}
+
+// SetLanguageTags annotates saved markdown with the detected language,
+// e.g. "_[en]_", at the start of the transcript and wherever it switches.
+// It only has an effect on transcripts made with language "auto"; use
+// TranscribeChunkedToFile to catch switches within one recording.
+func (t *TranscribeService) SetLanguageTags(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.languageTags = enabled
+}
+
+// tagSegmentLanguage sets the language of segments without one.
+func tagSegmentLanguage(segments []Segment, lang string) {
+	for i := range segments {
+		if segments[i].Language == "" {
+			segments[i].Language = lang
+		}
+	}
+}
+
+// segmentLanguages returns the distinct languages of segments in order,
+// or fallback if none has one.
+func segmentLanguages(segments []Segment, fallback string) []string {
+	var langs []string
+	for _, s := range segments {
+		if s.Language != "" && !slices.Contains(langs, s.Language) {
+			langs = append(langs, s.Language)
+		}
+	}
+	if langs == nil && fallback != "" {
+		langs = []string{fallback}
+	}
+	return langs
+}
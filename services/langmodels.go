@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SetLanguageModelMap chooses which model to use per language, e.g.
+// {"en": "base.en", "ja": "large-v3"}. Values are model names as used by
+// ModelService or paths to model files. Languages without an entry, or
+// whose model isn't installed, use the default model. The map is persisted
+// in settings and applied to the current language immediately.
+func (t *TranscribeService) SetLanguageModelMap(models map[string]string) error {
+	clean, err := cleanLanguageModels(models)
+	if err != nil {
+		return err
+	}
+	if err := updateSettings(func(s *Settings) { s.LanguageModels = clean }); err != nil {
+		return fmt.Errorf("failed to save language models: %w", err)
+	}
+	t.mu.Lock()
+	t.cfg.languageModels = clean
+	cfg := t.cfg
+	t.mu.Unlock()
+	t.setModelPath(cfg.findModelPath())
+	return nil
+}
+
+// cleanLanguageModels trims a language model map, rejecting empty entries.
+func cleanLanguageModels(models map[string]string) (map[string]string, error) {
+	clean := make(map[string]string, len(models))
+	for lang, model := range models {
+		lang, model = strings.TrimSpace(lang), strings.TrimSpace(model)
+		if lang == "" || model == "" {
+			return nil, fmt.Errorf("language and model cannot be empty")
+		}
+		clean[lang] = model
+	}
+	return clean, nil
+}
+
+// GetLanguageModelMap returns the per-language model choices.
+func (t *TranscribeService) GetLanguageModelMap() map[string]string {
+	return maps.Clone(t.config().languageModels)
+}
+
+// mappedModelPath returns the model mapped to the current language, or ""
+// if there is none or it can't be found.
+func (c transcribeConfig) mappedModelPath() string {
+	model, ok := c.languageModels[c.language]
+	if !ok {
+		return ""
+	}
+	if strings.ContainsRune(model, filepath.Separator) || filepath.Ext(model) == ".bin" {
+		if _, err := os.Stat(model); err == nil {
+			abs, _ := filepath.Abs(model)
+			return abs
+		}
+		return ""
+	}
+	if def, ok := findModelDefinition(model); ok {
+		return locateModel(def.FileName)
+	}
+	return locateModel("ggml-" + model + ".bin")
+}
+
+// locateModel looks for a model file in the same places as findModelPath.
+func locateModel(file string) string {
+	if _, err := os.Stat(filepath.Join("models", file)); err == nil {
+		abs, _ := filepath.Abs(filepath.Join("models", file))
+		return abs
+	}
+	for _, dir := range append(slices.Clone(homebrewModelDirs), modelsDir()) {
+		p := filepath.Join(dir, file)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	meterFloorDb = -90.0 // dBFS reported for silence
+
+	// Defaults for SetPeakHold
+	defaultPeakHoldMs        = 1500
+	defaultPeakDecayDbPerSec = 20
+)
+
+// InputLevel is the current input level in dBFS, for a level meter.
+type InputLevel struct {
+	PeakDb float64 `json:"peakDb"`
+	RMSDb  float64 `json:"rmsDb"`
+	// PeakHoldDb is the highest recent peak. It's held for the configured
+	// time, then falls at the configured rate; see SetPeakHold.
+	PeakHoldDb float64 `json:"peakHoldDb"`
+	// MicRMSDb and SystemRMSDb are the two sources, after their gains,
+	// while system audio is mixed in (see SelectSystemAudioDevice); the
+	// other levels are then of the mix. Otherwise they're the floor.
+	MicRMSDb    float64 `json:"micRmsDb"`
+	SystemRMSDb float64 `json:"systemRmsDb"`
+}
+
+// GetInputLevel returns the level of the latest input buffer. Peaks are
+// tracked in the audio callback, so PeakHoldDb catches transients that fall
+// between polls.
+func (a *AudioService) GetInputLevel() InputLevel {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	level := InputLevel{
+		PeakDb:      toDbFS(a.levelPeak),
+		RMSDb:       toDbFS(a.levelRMS),
+		PeakHoldDb:  meterFloorDb,
+		MicRMSDb:    meterFloorDb,
+		SystemRMSDb: meterFloorDb,
+	}
+	if a.system != nil {
+		level.MicRMSDb = toDbFS(a.levelMicRMS)
+		level.SystemRMSDb = toDbFS(a.levelSystemRMS)
+	}
+	if a.holdPeak > 0 {
+		hold := toDbFS(a.holdPeak)
+		if over := time.Since(a.holdSince) - a.peakHoldTime; over > 0 {
+			hold -= a.peakDecay * over.Seconds()
+		}
+		level.PeakHoldDb = math.Max(math.Max(hold, level.PeakDb), meterFloorDb)
+	}
+	return level
+}
+
+// SetPeakHold sets how long the meter's peak hold stays put (0-10000 ms) and
+// how fast it then falls (1-1000 dB per second).
+func (a *AudioService) SetPeakHold(holdMs, decayDbPerSec float64) error {
+	if holdMs < 0 || holdMs > 10000 {
+		return fmt.Errorf("peak hold must be between 0 and 10000 ms")
+	}
+	if decayDbPerSec < 1 || decayDbPerSec > 1000 {
+		return fmt.Errorf("peak decay must be between 1 and 1000 dB/s")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.peakHoldTime = time.Duration(holdMs * float64(time.Millisecond))
+	a.peakDecay = decayDbPerSec
+	return nil
+}
+
+// trackLevel updates the meter from a callback buffer. Callers must hold a.mu.
+func (a *AudioService) trackLevel(in []int16) {
+	peak := 0
+	for _, s := range in {
+		peak = max(peak, abs(int(s)))
+	}
+	a.levelPeak = float64(peak) / int16FullScale
+	a.levelRMS = rms(in) / int16FullScale
+
+	// Replace the held peak once it has decayed below the new one
+	now := time.Now()
+	held := a.holdPeak
+	if over := now.Sub(a.holdSince) - a.peakHoldTime; over > 0 && held > 0 {
+		held *= math.Pow(10, -a.peakDecay*over.Seconds()/20)
+	}
+	if a.levelPeak >= held {
+		a.holdPeak = a.levelPeak
+		a.holdSince = now
+	}
+}
+
+// resetLevel clears the meter for a new recording. Callers must hold a.mu.
+func (a *AudioService) resetLevel() {
+	a.levelPeak, a.levelRMS = 0, 0
+	a.levelMicRMS, a.levelSystemRMS = 0, 0
+	a.holdPeak = 0
+	a.holdSince = time.Time{}
+}
+
+func toDbFS(v float64) float64 {
+	if v <= 0 {
+		return meterFloorDb
+	}
+	return math.Max(20*math.Log10(v), meterFloorDb)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+const (
+	// liveInterval is how often a new window is sent to whisper. Runs that
+	// take longer simply delay the next one.
+	liveInterval = 3 * time.Second
+	// liveOverlap is re-transcribed before the committed point so words cut
+	// at the previous window edge are heard in full.
+	liveOverlap = 5 * time.Second
+	// liveMaxWindow caps the window at whisper's native 30s context. If
+	// whisper falls further behind than this, the skipped audio only shows up
+	// in the final transcript.
+	liveMaxWindow = 30 * time.Second
+	// liveHoldBack keeps segments this close to the end of the window
+	// provisional, since the speaker is probably still mid-sentence.
+	liveHoldBack = 1500 * time.Millisecond
+	// liveMinAudio is the shortest window worth transcribing
+	liveMinAudio = time.Second
+)
+
+// LiveCaption is emitted as "transcribe:live" while live transcription runs.
+type LiveCaption struct {
+	RecordingID string `json:"recordingId"`
+	// Text is newly finalized text to append to the caption
+	Text string `json:"text"`
+	// Pending is provisional text for the most recent audio. It replaces
+	// the previous event's Pending and may change in the next one.
+	Pending string `json:"pending"`
+	// End is the recording time in seconds that finalized text covers
+	End float64 `json:"end"`
+}
+
+// LiveService shows captions while a meeting is being recorded by running
+// whisper on a rolling window of the audio captured so far.
+type LiveService struct {
+	audio      *AudioService
+	transcribe *TranscribeService
+
+	mu      sync.Mutex
+	session *liveSession
+}
+
+type liveSession struct {
+	cancel context.CancelFunc
+}
+
+func NewLiveService(audio *AudioService, transcribe *TranscribeService) *LiveService {
+	return &LiveService{audio: audio, transcribe: transcribe}
+}
+
+func (l *LiveService) ServiceName() string {
+	return "LiveService"
+}
+
+func (l *LiveService) ServiceStartup(_ context.Context, _ application.ServiceOptions) error {
+	return nil
+}
+
+func (l *LiveService) ServiceShutdown() error {
+	l.StopLiveTranscription()
+	return nil
+}
+
+// StartLiveTranscription emits "transcribe:live" captions for the current
+// recording until it stops or StopLiveTranscription is called.
+//
+// Whisper re-runs on up to 30s of audio every few seconds, which keeps one
+// or more CPU cores busy for the whole meeting. Only the tiny and base
+// models are fast enough to keep up; the final transcript is still produced
+// from the full recording as usual.
+func (l *LiveService) StartLiveTranscription() error {
+	if !l.transcribe.IsWhisperAvailable() {
+		return fmt.Errorf("whisper-cpp is not installed. Please install it with: brew install whisper-cpp")
+	}
+	modelPath := l.transcribe.GetModelPath()
+	if modelPath == "" {
+		return fmt.Errorf("whisper model not found. Please download a model file")
+	}
+	if modelSpeedFactor(modelPath) > modelSpeedFactors["base"] {
+		return fmt.Errorf("live transcription needs the tiny or base model; the current model is too slow to keep up")
+	}
+
+	done, ok := l.audio.recordingStopped()
+	if !ok {
+		return fmt.Errorf("live transcription requires an active recording")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.session != nil {
+		return fmt.Errorf("live transcription is already running")
+	}
+
+	ctx, cancel := context.WithCancel(l.transcribe.runContext())
+	session := &liveSession{cancel: cancel}
+	l.session = session
+	go func() {
+		// Stopping the recording kills any whisper run in progress
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	go l.run(ctx, session, l.audio.GetRecordingID())
+	return nil
+}
+
+// StopLiveTranscription stops emitting captions. Recording continues.
+func (l *LiveService) StopLiveTranscription() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.session != nil {
+		l.session.cancel()
+		l.session = nil
+	}
+}
+
+func (l *LiveService) run(ctx context.Context, session *liveSession, recordingID string) {
+	defer func() {
+		session.cancel()
+		l.mu.Lock()
+		// A newer session may have started after this one was stopped
+		if l.session == session {
+			l.session = nil
+		}
+		l.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(liveInterval)
+	defer ticker.Stop()
+
+	var committed, lastEnd float64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		committed, lastEnd = l.step(ctx, recordingID, committed, lastEnd)
+	}
+}
+
+// step transcribes the audio after committed (plus overlap) and emits the
+// new caption text. It returns the updated committed point and window end.
+func (l *LiveService) step(ctx context.Context, recordingID string, committed, lastEnd float64) (float64, float64) {
+	samples, sr, start, end := l.audio.liveWindow(committed-liveOverlap.Seconds(), liveMaxWindow.Seconds())
+	// Nothing new while paused
+	if end <= lastEnd || end-start < liveMinAudio.Seconds() {
+		return committed, lastEnd
+	}
+
+	f, err := os.CreateTemp("", "meeting_live_*.wav")
+	if err != nil {
+		log.Printf("live transcription: %v", err)
+		return committed, lastEnd
+	}
+	wavPath := f.Name()
+	f.Close()
+	defer os.Remove(wavPath)
+
+	if err := writePCMWAV(wavPath, samples, sr, channels); err != nil {
+		log.Printf("live transcription: %v", err)
+		return committed, lastEnd
+	}
+
+	result, err := l.transcribe.transcribeContext(ctx, l.transcribe.config(), wavPath)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("live transcription: %v", err)
+		}
+		return committed, lastEnd
+	}
+
+	caption, newCommitted := liveCaption(result, start, end, committed)
+	caption.RecordingID = recordingID
+	if ctx.Err() == nil {
+		application.Get().Event.Emit("transcribe:live", caption)
+	}
+	return newCommitted, end
+}
+
+// liveCaption splits a window's segments into finalized and provisional text.
+// Segments are placed on the recording timeline using the window start; those
+// centred before committed were already emitted by an earlier window, which
+// deduplicates the overlap without relying on the words matching exactly.
+func liveCaption(result transcription, start, end, committed float64) (LiveCaption, float64) {
+	if len(result.Segments) == 0 {
+		// Without timings nothing can be finalized safely
+		return LiveCaption{Pending: result.Text, End: committed}, committed
+	}
+
+	finalizeBefore := end - liveHoldBack.Seconds()
+	var final, pending []string
+	for _, s := range result.Segments {
+		segStart, segEnd := start+s.Start, start+s.End
+		if (segStart+segEnd)/2 <= committed || s.Text == "" {
+			continue
+		}
+		if segEnd <= finalizeBefore && len(pending) == 0 {
+			final = append(final, s.Text)
+			committed = segEnd
+		} else {
+			pending = append(pending, s.Text)
+		}
+	}
+
+	return LiveCaption{
+		Text:    strings.Join(final, " "),
+		Pending: strings.Join(pending, " "),
+		End:     committed,
+	}, committed
+}
+
+// recordingStopped returns a channel that is closed when the current
+// recording stops. ok is false when nothing is being recorded.
+func (a *AudioService) recordingStopped() (done <-chan struct{}, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.state != stateRecording && a.state != statePaused {
+		return nil, false
+	}
+	return a.recordingDone, true
+}
+
+// liveWindow returns the recording from `from` seconds onward as mono audio
+// at the transcription sample rate, limited to the last maxSeconds. start and
+// end are the window's position in the recording, in seconds.
+func (a *AudioService) liveWindow(from, maxSeconds float64) (samples []int16, sr int, start, end float64) {
+	a.mu.Lock()
+	// Audio already saved as segments is no longer in a.samples, but
+	// positions stay relative to the start of the recording
+	offset := a.segmentFrames
+	frames := offset + len(a.samples)/max(a.numChannels, 1)
+	nativeSR := a.nativeSR
+	numChannels := a.numChannels
+	sr = a.transcriptionRate()
+	if nativeSR == 0 {
+		a.mu.Unlock()
+		return nil, sr, 0, 0
+	}
+
+	end = float64(frames) / nativeSR
+	start = max(from, end-maxSeconds, float64(offset)/nativeSR)
+	// from can be past the captured audio; keep the window from inverting
+	first := min(max(int(start*nativeSR), offset), frames)
+	raw := make([]int16, (frames-first)*numChannels)
+	copy(raw, a.samples[(first-offset)*numChannels:(frames-offset)*numChannels])
+	var system []int16
+	if len(a.systemSamples) > 0 {
+		system = make([]int16, frames-first)
+		copy(system, a.systemSamples[first-offset:])
+	}
+	micDb, systemDb, balance := a.micGainDb, a.systemGainDb, a.autoBalance
+	a.mu.Unlock()
+
+	// Convert outside the lock so the audio callback isn't held up
+	mono := mixToMono(raw, numChannels)
+	if system != nil {
+		mono = mixSystemAudio(mono, system, nativeSR, micDb, systemDb, balance)
+	}
+	return resample(mono, nativeSR, float64(sr), nil), sr, start, end
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const (
+	wordsPerMinute    = 200 // typical reading speed for space-delimited text
+	cjkCharsPerMinute = 500 // typical reading speed for Japanese/Chinese text
+)
+
+// TranscriptStats summarizes the length of a transcript.
+type TranscriptStats struct {
+	WordCount      int `json:"wordCount"`
+	CharCount      int `json:"charCount"`
+	ReadingMinutes int `json:"readingMinutes"`
+}
+
+const defaultMarkdownTemplate = `{{.FrontMatter}}{{.Heading}} {{with .Title}}{{.}}{{else}}Meeting Transcription{{end}}
+
+**Date:** {{.Date}}
+{{- if .Part}}
+
+**Part:** {{.Part}}
+{{- end}}
+{{- if .IncludeStats}}
+
+**Length:** {{.WordCount}} words · ~{{.ReadingMinutes}} min read
+{{- end}}
+
+---
+
+{{if .Collapsible -}}
+<details>
+<summary>Transcript</summary>
+
+{{.Text}}
+
+</details>
+{{- else -}}
+{{.Text}}
+{{- end}}
+`
+
+var markdownTemplate = template.Must(template.New("markdown").Parse(defaultMarkdownTemplate))
+
+// markdownData is the data available to the markdown template.
+type markdownData struct {
+	TranscriptStats
+	FrontMatter  string // rendered YAML block, or "" without metadata
+	Title        string
+	Date         string
+	Part         string // e.g. "2 of 3" for split transcripts
+	Text         string
+	IncludeStats bool
+	Heading      string // "#" repeated for the heading level
+	Collapsible  bool   // wrap Text in a <details> block
+}
+
+func renderMarkdown(data markdownData) (string, error) {
+	var sb strings.Builder
+	if err := markdownTemplate.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// computeTranscriptStats counts words and characters in text. Han, Hiragana
+// and Katakana have no spaces between words, so each such character counts
+// as one word and is read at a per-character rate instead.
+func computeTranscriptStats(text string) TranscriptStats {
+	var stats TranscriptStats
+	var cjkChars, words int
+	inWord := false
+
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		stats.CharCount++
+
+		if isCJK(r) {
+			cjkChars++
+			inWord = false
+			continue
+		}
+		if !inWord && !unicode.IsPunct(r) {
+			words++
+			inWord = true
+		}
+	}
+
+	stats.WordCount = words + cjkChars
+
+	minutes := float64(words)/wordsPerMinute + float64(cjkChars)/cjkCharsPerMinute
+	stats.ReadingMinutes = int(minutes + 0.5)
+	if stats.ReadingMinutes == 0 && stats.WordCount > 0 {
+		stats.ReadingMinutes = 1
+	}
+
+	return stats
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeTranscriptStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantWords int
+		wantChars int
+	}{
+		{"english", "Let's ship it on Friday.", 5, 20},
+		{"punctuation alone isn't a word", "well - ok ... yes", 3, 13},
+		{"japanese counts characters", "今日は会議です。", 7, 8},
+		{"chinese", "我们明天开会", 6, 6},
+		{"katakana", "ミーティング", 6, 6},
+		{"mixed", "今日は Zoom meeting です", 7, 16},
+		{"cjk inside a latin word splits it", "abc会議def", 4, 8},
+		{"korean uses spaces", "안녕하세요 여러분", 2, 8},
+		{"empty", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeTranscriptStats(tt.text)
+			if got.WordCount != tt.wantWords || got.CharCount != tt.wantChars {
+				t.Errorf("computeTranscriptStats(%q) = %d words, %d chars; want %d, %d",
+					tt.text, got.WordCount, got.CharCount, tt.wantWords, tt.wantChars)
+			}
+		})
+	}
+}
+
+func TestComputeTranscriptStatsReadingTime(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"short rounds up to a minute", "hello", 1},
+		{"words", strings.Repeat("word ", 1000), 5},
+		{"cjk at the character rate", strings.Repeat("会議", 750), 3},
+	}
+	for _, tt := range tests {
+		if got := computeTranscriptStats(tt.text).ReadingMinutes; got != tt.want {
+			t.Errorf("%s: ReadingMinutes = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrBelowMinLength is returned when a recording is shorter than the
+// SetMinTranscribeLength threshold.
+var ErrBelowMinLength = errors.New("recording is shorter than the minimum length for transcription")
+
+// SetMinTranscribeLength makes TranscribeToFile refuse recordings shorter
+// than seconds with ErrBelowMinLength, so accidental micro-recordings (for
+// example with auto-transcribe) aren't transcribed. Zero, the default,
+// disables the check.
+func (t *TranscribeService) SetMinTranscribeLength(seconds float64) error {
+	if seconds < 0 {
+		return fmt.Errorf("minimum length must not be negative")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.minLength = seconds
+	return nil
+}
+
+// checkMinLength applies SetMinTranscribeLength to wavPath. Files whose
+// length can't be read cheaply, such as FLAC imports, pass.
+func (c transcribeConfig) checkMinLength(wavPath string) error {
+	if c.minLength <= 0 {
+		return nil
+	}
+
+	duration := recordingDuration(wavPath)
+	if duration == 0 {
+		return nil
+	}
+	if duration < c.minLength {
+		return fmt.Errorf("%w: %.1fs is below %.1fs", ErrBelowMinLength, duration, c.minLength)
+	}
+	return nil
+}
+
+// recordingDuration returns the length of wavPath in seconds. Recordings
+// from this session use their recorded duration, which excludes pauses;
+// other WAVs, and single segments of a recording, use their header. Returns
+// 0 if the length can't be read cheaply, as for FLAC imports.
+func recordingDuration(wavPath string) float64 {
+	info, ok := recordings.get(recordings.idForPath(wavPath))
+	if ok && info.Duration > 0 && !slices.Contains(info.SegmentPaths, wavPath) {
+		return info.Duration
+	}
+	if isCompressedAudio(wavPath) {
+		return 0
+	}
+	d, _ := wavDuration(wavPath)
+	return d
+}
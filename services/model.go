@@ -2,8 +2,13 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -18,7 +23,10 @@ type ModelInfo struct {
 	FileName string `json:"fileName"`
 	Size     string `json:"size"`
 	URL      string `json:"url"`
-	Exists   bool   `json:"exists"`
+	SHA256   string `json:"sha256,omitempty"` // checked after download when set
+	// Bytes is the exact size once RefreshCatalogSizes has fetched it
+	Bytes  int64 `json:"bytes,omitempty"`
+	Exists bool  `json:"exists"`
 }
 
 type DownloadProgress struct {
@@ -28,12 +36,48 @@ type DownloadProgress struct {
 	Percent     float64 `json:"percent"`
 	Done        bool    `json:"done"`
 	Error       string  `json:"error,omitempty"`
+	// Verifying is set while VerifyModel hashes the file; the byte counts
+	// then refer to the hash progress
+	Verifying bool `json:"verifying,omitempty"`
+	// PartialKept is set when a download stopped early but its partial file
+	// was kept, so DownloadModel will resume it
+	PartialKept bool `json:"partialKept,omitempty"`
+	// Interrupted is set on the final event of a download stopped by the
+	// app quitting
+	Interrupted bool `json:"interrupted,omitempty"`
 }
 
+const (
+	defaultDownloadBufferSize = 32 * 1024
+	minDownloadBufferSize     = 8 * 1024
+	maxDownloadBufferSize     = 1024 * 1024
+
+	defaultConnectTimeout = 30 * time.Second
+	defaultIdleTimeout    = 60 * time.Second
+)
+
 type ModelService struct {
-	mu          sync.Mutex
-	cancelFunc  context.CancelFunc
-	downloading bool
+	mu sync.Mutex
+	// Active downloads keyed by model name
+	progress map[string]*DownloadProgress
+	cancels  map[string]context.CancelCauseFunc
+	// Active VerifyModel runs keyed by model name
+	verifyCancels map[string]context.CancelFunc
+	// Active ProbeDownloadSpeed runs keyed by model name
+	probeCancels map[string]context.CancelFunc
+	// sizesCancel stops a running RefreshCatalogSizes
+	sizesCancel context.CancelFunc
+
+	// Models waiting to download one at a time; see QueueDownloads
+	queue        []string
+	queueActive  string
+	queueRunning bool
+
+	bufferSize int         // read buffer size for downloads; 0 means default
+	fileMode   os.FileMode // see SetModelFileMode; 0 means 0644
+	// Download timeouts; 0 means default
+	connectTimeout time.Duration
+	idleTimeout    time.Duration
 }
 
 var modelDefinitions = []ModelInfo{
@@ -67,97 +111,404 @@ func (m *ModelService) ServiceName() string {
 	return "ModelService"
 }
 
+// ServiceStartup loads the saved catalog and clears out partial downloads
+// that no catalog model can resume; see ResumableDownloads for the rest.
+// If the saved catalog can't be loaded its partial files are kept.
 func (m *ModelService) ServiceStartup(_ context.Context, _ application.ServiceOptions) error {
+	if loadSavedModelCatalog() {
+		removeOrphanedParts(m.GetModelsDir())
+	}
 	return nil
 }
 
+// ServiceShutdown stops downloads so they can be resumed next launch. The
+// download goroutines may not get to report that before the app exits, so
+// a final interrupted snapshot of each is emitted here.
 func (m *ModelService) ServiceShutdown() error {
+	m.clearQueue()
+	m.mu.Lock()
+	var interrupted []DownloadProgress
+	for name, cancel := range m.cancels {
+		cancel(errDownloadInterrupted)
+		delete(m.cancels, name)
+		if p := m.progress[name]; p != nil {
+			interrupted = append(interrupted, *p)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, p := range interrupted {
+		p.Done = false
+		p.Error = "interrupted"
+		p.PartialKept = true
+		p.Interrupted = true
+		application.Get().Event.Emit("model:download-progress", p)
+	}
 	m.CancelDownload()
 	return nil
 }
 
+// ModelsDirFallback is emitted as "model:dir-fallback" when the usual models
+// directory under the home directory can't be used.
+type ModelsDirFallback struct {
+	Dir    string `json:"dir"`
+	Reason string `json:"reason"`
+}
+
+var (
+	modelsDirOnce sync.Once
+	modelsDirPath string
+)
+
+// GetModelsDir returns where models are downloaded to, normally
+// ~/.local/share/whisper-cpp/models.
 func (m *ModelService) GetModelsDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil || home == "" {
-		return ""
+	return modelsDir()
+}
+
+// modelsDir resolves the models directory once. Without a home directory
+// (sandboxed or headless environments) it falls back to $XDG_DATA_HOME, a
+// models directory next to the executable, and finally the temp directory.
+func modelsDir() string {
+	modelsDirOnce.Do(func() {
+		dir, reason := resolveModelsDir()
+		modelsDirPath = dir
+		if reason == "" {
+			return
+		}
+		log.Printf("home directory unavailable; using %s for models (%s)", dir, reason)
+		if app := application.Get(); app != nil {
+			app.Event.Emit("model:dir-fallback", ModelsDirFallback{Dir: dir, Reason: reason})
+		}
+	})
+	return modelsDirPath
+}
+
+// resolveModelsDir returns the models directory and, if it isn't the usual
+// one, a description of the fallback used.
+func resolveModelsDir() (dir, fallback string) {
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		return filepath.Join(home, ".local", "share", "whisper-cpp", "models"), ""
+	}
+	if data := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(data) {
+		return filepath.Join(data, "whisper-cpp", "models"), "XDG_DATA_HOME"
+	}
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Join(filepath.Dir(exe), "models")
+		if dirWritable(dir) {
+			return dir, "next to the executable"
+		}
 	}
-	return filepath.Join(home, ".local", "share", "whisper-cpp", "models")
+	return filepath.Join(os.TempDir(), "whisper-cpp-models"), "temporary directory; models may be deleted by the system"
+}
+
+// dirWritable reports whether files can be created in dir. Directories
+// created for the check are removed again; downloads create them as needed.
+func dirWritable(dir string) bool {
+	// The outermost directory that doesn't exist yet
+	created := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		created = d
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if created != "" {
+		defer os.RemoveAll(created)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".writetest-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
 }
 
 func (m *ModelService) ListModels() []ModelInfo {
 	dir := m.GetModelsDir()
-	models := make([]ModelInfo, len(modelDefinitions))
-	for i, def := range modelDefinitions {
-		models[i] = def
-		if dir != "" {
-			p := filepath.Join(dir, def.FileName)
-			if _, err := os.Stat(p); err == nil {
-				models[i].Exists = true
-			}
+	models := modelCatalog()
+	for i, def := range models {
+		p := filepath.Join(dir, def.FileName)
+		if _, err := os.Stat(p); err == nil {
+			models[i].Exists = true
 		}
 	}
 	return models
 }
 
-func (m *ModelService) DownloadModel(name string) error {
+// DeleteModel removes a downloaded model from the models directory.
+func (m *ModelService) DeleteModel(name string) error {
+	model, ok := findModelDefinition(name)
+	if !ok {
+		return fmt.Errorf("unknown model: %s", name)
+	}
+
 	m.mu.Lock()
-	if m.downloading {
-		m.mu.Unlock()
-		return fmt.Errorf("a download is already in progress")
+	_, downloading := m.progress[name]
+	m.mu.Unlock()
+	if downloading {
+		return fmt.Errorf("model %s is downloading; cancel the download first", name)
 	}
 
-	var model *ModelInfo
-	for _, def := range modelDefinitions {
-		if def.Name == name {
-			model = &def
-			break
-		}
+	path := filepath.Join(m.GetModelsDir(), model.FileName)
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("model %s is not installed", name)
 	}
-	if model == nil {
-		m.mu.Unlock()
-		return fmt.Errorf("unknown model: %s", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete model: %w", err)
+	}
+	os.Remove(path + checksumSuffix)
+	return nil
+}
+
+// ImportModel copies a model file from srcPath into the models directory
+// after checking that its header is a valid ggml/gguf whisper model.
+func (m *ModelService) ImportModel(srcPath string) (ModelMetadata, error) {
+	meta, err := readModelMetadata(srcPath)
+	if err != nil {
+		return meta, err
 	}
 
 	dir := m.GetModelsDir()
-	if dir == "" {
-		m.mu.Unlock()
-		return fmt.Errorf("cannot determine models directory")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return meta, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	m.cancelFunc = cancel
-	m.downloading = true
-	m.mu.Unlock()
+	dstPath := filepath.Join(dir, filepath.Base(srcPath))
+	if _, err := os.Stat(dstPath); err == nil {
+		return meta, fmt.Errorf("a model named %s already exists", filepath.Base(srcPath))
+	}
 
-	go m.doDownload(ctx, *model, dir)
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return meta, fmt.Errorf("cannot open model: %w", err)
+	}
+	defer src.Close()
+
+	partPath := dstPath + ".part"
+	dst, err := m.openPart(partPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return meta, fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(partPath)
+		return meta, fmt.Errorf("failed to copy model: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(partPath)
+		return meta, fmt.Errorf("failed to copy model: %w", err)
+	}
+	if err := os.Rename(partPath, dstPath); err != nil {
+		os.Remove(partPath)
+		return meta, fmt.Errorf("failed to finalize file: %w", err)
+	}
+	if err := m.applyModelModes(dir, dstPath); err != nil {
+		log.Printf("%s: %v", filepath.Base(dstPath), err)
+	}
+	return meta, nil
+}
+
+func (m *ModelService) DownloadModel(name string) error {
+	ctx, model, dir, err := m.beginDownload(name)
+	if err != nil {
+		return err
+	}
+	go m.doDownload(ctx, model, dir)
 	return nil
 }
 
+// beginDownload registers a download of name, returning what doDownload
+// needs to run it.
+func (m *ModelService) beginDownload(name string) (context.Context, ModelInfo, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.progress[name]; ok {
+		return nil, ModelInfo{}, "", fmt.Errorf("model %s is already downloading", name)
+	}
+
+	model, ok := findModelDefinition(name)
+	if !ok {
+		return nil, ModelInfo{}, "", fmt.Errorf("unknown model: %s", name)
+	}
+
+	dir := m.GetModelsDir()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	if m.progress == nil {
+		m.progress = make(map[string]*DownloadProgress)
+		m.cancels = make(map[string]context.CancelCauseFunc)
+	}
+	m.progress[name] = &DownloadProgress{ModelName: name}
+	m.cancels[name] = cancel
+	return ctx, model, dir, nil
+}
+
+// Causes for stopping a download
+var (
+	errDownloadPaused      = errors.New("download paused")
+	errDownloadAborted     = errors.New("download aborted")
+	errDownloadStalled     = errors.New("download stalled")
+	errDownloadInterrupted = errors.New("download interrupted")
+)
+
+// CancelDownload stops all active downloads, verifications, speed probes
+// and any running RefreshCatalogSizes, and clears the download queue.
+// Partial downloads are kept, so calling DownloadModel again resumes where
+// it left off; the progress event reports "paused" with PartialKept set.
 func (m *ModelService) CancelDownload() error {
+	m.clearQueue()
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if m.cancelFunc != nil {
-		m.cancelFunc()
-		m.cancelFunc = nil
+	for name, cancel := range m.cancels {
+		cancel(errDownloadPaused)
+		delete(m.cancels, name)
+	}
+	for name, cancel := range m.verifyCancels {
+		cancel()
+		delete(m.verifyCancels, name)
+	}
+	for name, cancel := range m.probeCancels {
+		cancel()
+		delete(m.probeCancels, name)
+	}
+	if m.sizesCancel != nil {
+		m.sizesCancel()
+	}
+	return nil
+}
+
+// AbortDownload stops all active downloads, clears the download queue and
+// deletes partial files, along with any left from earlier paused downloads.
+// The progress event of an active download reports "cancelled".
+func (m *ModelService) AbortDownload() error {
+	m.clearQueue()
+	m.mu.Lock()
+	for name, cancel := range m.cancels {
+		cancel(errDownloadAborted)
+		delete(m.cancels, name)
+	}
+	active := make(map[string]bool, len(m.progress))
+	for name := range m.progress {
+		active[name] = true
+	}
+	m.mu.Unlock()
+
+	// Active downloads remove their own file once they've stopped writing
+	dir := m.GetModelsDir()
+	for _, def := range modelCatalog() {
+		if !active[def.Name] {
+			os.Remove(filepath.Join(dir, def.FileName) + ".part")
+		}
 	}
 	return nil
 }
 
+// SetDownloadBufferSize sets the read buffer used for model downloads.
+// Larger buffers reduce syscall overhead on very fast connections.
+func (m *ModelService) SetDownloadBufferSize(bytes int) error {
+	if bytes < minDownloadBufferSize || bytes > maxDownloadBufferSize {
+		return fmt.Errorf("buffer size must be between %d and %d bytes", minDownloadBufferSize, maxDownloadBufferSize)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bufferSize = bytes
+	return nil
+}
+
+func (m *ModelService) downloadBufferSize() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.bufferSize == 0 {
+		return defaultDownloadBufferSize
+	}
+	return m.bufferSize
+}
+
+// SetDownloadTimeouts sets how long a download may take to connect, and how
+// long it may then go without receiving data before it fails. A stalled
+// download keeps its partial file, so retrying resumes it.
+func (m *ModelService) SetDownloadTimeouts(connect, idle time.Duration) error {
+	if connect <= 0 || idle <= 0 {
+		return fmt.Errorf("download timeouts must be positive")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connectTimeout = connect
+	m.idleTimeout = idle
+	return nil
+}
+
+func (m *ModelService) downloadTimeouts() (connect, idle time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	connect, idle = m.connectTimeout, m.idleTimeout
+	if connect == 0 {
+		connect = defaultConnectTimeout
+	}
+	if idle == 0 {
+		idle = defaultIdleTimeout
+	}
+	return connect, idle
+}
+
+// downloadClient returns an HTTP client that gives up on connections that
+// can't be established within connect or send no headers within idle.
+func downloadClient(connect, idle time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connect
+	transport.ResponseHeaderTimeout = idle
+	return &http.Client{Transport: transport}
+}
+
 func (m *ModelService) IsDownloading() bool {
+	return m.AnyDownloading()
+}
+
+// AnyDownloading reports whether any model is currently downloading.
+func (m *ModelService) AnyDownloading() bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.downloading
+	return len(m.progress) > 0
+}
+
+// GetModelProgress returns the latest progress of the named model's
+// download, or nil if it isn't downloading.
+func (m *ModelService) GetModelProgress(name string) *DownloadProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.progress[name]
+	if !ok {
+		return nil
+	}
+	cp := *p
+	return &cp
 }
 
 func (m *ModelService) doDownload(ctx context.Context, model ModelInfo, dir string) {
 	defer func() {
 		m.mu.Lock()
-		m.downloading = false
-		m.cancelFunc = nil
+		if cancel, ok := m.cancels[model.Name]; ok {
+			cancel(nil)
+			delete(m.cancels, model.Name)
+		}
+		delete(m.progress, model.Name)
 		m.mu.Unlock()
 	}()
 
 	emit := func(p DownloadProgress) {
+		m.mu.Lock()
+		m.progress[model.Name] = &p
+		m.mu.Unlock()
 		application.Get().Event.Emit("model:download-progress", p)
 	}
 
@@ -178,49 +529,106 @@ func (m *ModelService) doDownload(ctx context.Context, model ModelInfo, dir stri
 	finalPath := filepath.Join(dir, model.FileName)
 	partPath := finalPath + ".part"
 
-	req, err := http.NewRequestWithContext(ctx, "GET", model.URL, nil)
+	// stopped reports a cancelled download, keeping the partial file for
+	// resuming unless it was aborted
+	stopped := func(loaded, total int64) {
+		if errors.Is(context.Cause(ctx), errDownloadInterrupted) {
+			// ServiceShutdown already reported it
+			return
+		}
+		if errors.Is(context.Cause(ctx), errDownloadAborted) {
+			os.Remove(partPath)
+			emit(DownloadProgress{ModelName: model.Name, Error: "cancelled"})
+			return
+		}
+		emit(DownloadProgress{ModelName: model.Name, BytesLoaded: loaded, BytesTotal: total, Error: "paused", PartialKept: true})
+	}
+
+	// The request is also cancelled when no data arrives for idle, which
+	// the read loop reports as a stall rather than a pause
+	connect, idle := m.downloadTimeouts()
+	reqCtx, cancelReq := context.WithCancelCause(ctx)
+	defer cancelReq(nil)
+	stall := time.AfterFunc(idle, func() { cancelReq(errDownloadStalled) })
+	defer stall.Stop()
+
+	req, err := http.NewRequestWithContext(reqCtx, "GET", model.URL, nil)
 	if err != nil {
 		emit(DownloadProgress{ModelName: model.Name, Error: fmt.Sprintf("failed to create request: %v", err)})
 		return
 	}
+	// Continue from a download that was paused or interrupted
+	var offset int64
+	if fi, err := os.Stat(partPath); err == nil && fi.Size() > 0 {
+		offset = fi.Size()
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := downloadClient(connect, idle).Do(req)
 	if err != nil {
-		if ctx.Err() == context.Canceled {
-			os.Remove(partPath)
-			emit(DownloadProgress{ModelName: model.Name, Error: "cancelled"})
+		if ctx.Err() != nil {
+			stopped(offset, 0)
 			return
 		}
+		if errors.Is(context.Cause(reqCtx), errDownloadStalled) {
+			err = fmt.Errorf("no response for %s", idle)
+		}
 		emit(DownloadProgress{ModelName: model.Name, Error: fmt.Sprintf("download failed: %v", err)})
 		return
 	}
 	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
+	// Resuming re-hashes the partial file first; the read loop re-arms this
+	stall.Stop()
+
+	// Hash while writing so verification needs no second pass over the file
+	hasher := sha256.New()
+	var f *os.File
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		f, err = m.openPart(partPath, os.O_RDWR)
+		if err == nil {
+			// Bring the hash up to date with what was downloaded before
+			_, err = io.Copy(hasher, f)
+		}
+	case resp.StatusCode == http.StatusOK:
+		// No range support (or nothing to resume): start over
+		offset = 0
+		f, err = m.openPart(partPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		os.Remove(partPath)
+		emit(DownloadProgress{ModelName: model.Name, Error: "the partial download could not be resumed; please try again"})
+		return
+	default:
 		emit(DownloadProgress{ModelName: model.Name, Error: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, resp.Status)})
 		return
 	}
-
-	total := resp.ContentLength
-
-	f, err := os.Create(partPath)
 	if err != nil {
-		emit(DownloadProgress{ModelName: model.Name, Error: fmt.Sprintf("failed to create file: %v", err)})
+		if f != nil {
+			f.Close()
+		}
+		emit(DownloadProgress{ModelName: model.Name, Error: fmt.Sprintf("failed to open file: %v", err)})
 		return
 	}
 
-	buf := make([]byte, 32*1024)
-	var loaded int64
+	total := resp.ContentLength
+	if total >= 0 {
+		total += offset
+	}
+
+	buf := make([]byte, m.downloadBufferSize())
+	loaded := offset
 	lastEmit := time.Time{}
 	var downloadErr error
 
 	for {
+		stall.Reset(idle)
 		n, readErr := resp.Body.Read(buf)
 		if n > 0 {
 			if _, writeErr := f.Write(buf[:n]); writeErr != nil {
 				downloadErr = fmt.Errorf("write failed: %v", writeErr)
 				break
 			}
+			hasher.Write(buf[:n])
 			loaded += int64(n)
 
 			now := time.Now()
@@ -243,20 +651,35 @@ func (m *ModelService) doDownload(ctx context.Context, model ModelInfo, dir stri
 			if readErr == io.EOF {
 				break
 			}
-			if ctx.Err() == context.Canceled {
-				downloadErr = fmt.Errorf("cancelled")
-			} else {
-				downloadErr = fmt.Errorf("download failed: %v", readErr)
+			if ctx.Err() == nil && errors.Is(context.Cause(reqCtx), errDownloadStalled) {
+				downloadErr = fmt.Errorf("download stalled: no data received for %s", idle)
+				break
 			}
+			downloadErr = fmt.Errorf("download failed: %v", readErr)
 			break
 		}
 	}
 
 	f.Close()
 
+	if ctx.Err() != nil {
+		stopped(loaded, total)
+		return
+	}
 	if downloadErr != nil {
+		// Keep what was downloaded so retrying resumes
+		emit(DownloadProgress{ModelName: model.Name, BytesLoaded: loaded, BytesTotal: total, Error: downloadErr.Error(), PartialKept: true})
+		return
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	expected := model.SHA256
+	if expected == "" {
+		expected = expectedSHA256(resp)
+	}
+	if expected != "" && sum != expected {
 		os.Remove(partPath)
-		emit(DownloadProgress{ModelName: model.Name, Error: downloadErr.Error()})
+		emit(DownloadProgress{ModelName: model.Name, Error: "downloaded file is corrupt (checksum mismatch); please try again"})
 		return
 	}
 
@@ -265,6 +688,15 @@ func (m *ModelService) doDownload(ctx context.Context, model ModelInfo, dir stri
 		emit(DownloadProgress{ModelName: model.Name, Error: fmt.Sprintf("failed to finalize file: %v", err)})
 		return
 	}
+	written := []string{finalPath}
+	if err := os.WriteFile(finalPath+checksumSuffix, []byte(sum+"\n"), 0644); err != nil {
+		log.Printf("failed to save checksum for %s: %v", model.FileName, err)
+	} else {
+		written = append(written, finalPath+checksumSuffix)
+	}
+	if err := m.applyModelModes(dir, written...); err != nil {
+		log.Printf("%s: %v", model.FileName, err)
+	}
 
 	emit(DownloadProgress{
 		ModelName:   model.Name,
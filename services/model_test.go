@@ -0,0 +1,52 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirWritableLeavesNoDirectoryBehind(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "app", "models")
+
+	if !dirWritable(dir) {
+		t.Fatal("dirWritable() = false for a writable location")
+	}
+	if _, err := os.Stat(filepath.Join(root, "app")); !os.IsNotExist(err) {
+		t.Errorf("probe left %s behind", filepath.Join(root, "app"))
+	}
+}
+
+func TestDirWritableKeepsExistingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ggml-base.bin"), []byte("model"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !dirWritable(dir) {
+		t.Fatal("dirWritable() = false for a writable directory")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "ggml-base.bin" {
+		t.Errorf("directory contents changed to %v", entries)
+	}
+}
+
+func TestDirWritableReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions aren't enforced for root")
+	}
+	root := t.TempDir()
+	if err := os.Chmod(root, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(root, 0o755)
+
+	if dirWritable(filepath.Join(root, "models")) {
+		t.Error("dirWritable() = true under a read-only directory")
+	}
+}
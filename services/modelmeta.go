@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ggmlMagic = 0x67676d6c // "ggml" read as a little-endian uint32
+	ggufMagic = 0x46554747 // "GGUF" read as a little-endian uint32
+
+	// Multilingual whisper vocabularies have 51865 tokens (51866 in
+	// large-v3); English-only models have 51864.
+	multilingualVocabSize = 51865
+)
+
+// ModelMetadata describes a whisper model file as read from its header.
+type ModelMetadata struct {
+	Format       string `json:"format"`    // "ggml" or "gguf"
+	ModelType    string `json:"modelType"` // "tiny", "base", ... or "unknown"
+	VocabSize    int    `json:"vocabSize"`
+	Multilingual bool   `json:"multilingual"`
+	AudioLayers  int    `json:"audioLayers,omitempty"`
+	TextLayers   int    `json:"textLayers,omitempty"`
+}
+
+// modelTypesByLayers maps the encoder layer count to the model tier.
+var modelTypesByLayers = map[int]string{
+	4:  "tiny",
+	6:  "base",
+	12: "small",
+	24: "medium",
+	32: "large",
+}
+
+// ReadModelMetadata parses the header of a ggml or gguf whisper model to
+// report its type and whether it supports languages other than English.
+func (m *ModelService) ReadModelMetadata(path string) (ModelMetadata, error) {
+	return readModelMetadata(path)
+}
+
+func readModelMetadata(path string) (ModelMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ModelMetadata{}, fmt.Errorf("cannot open model: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var magic uint32
+	if err := binary.Read(r, binary.LittleEndian, &magic); err != nil {
+		return ModelMetadata{}, fmt.Errorf("model file is too short: %w", err)
+	}
+
+	switch magic {
+	case ggmlMagic:
+		return readGGMLHeader(r)
+	case ggufMagic:
+		return readGGUFHeader(r)
+	default:
+		return ModelMetadata{}, fmt.Errorf("unrecognized model format (magic %#08x); expected a ggml or gguf whisper model", magic)
+	}
+}
+
+// readGGMLHeader reads the whisper hyperparameters that follow the magic.
+func readGGMLHeader(r io.Reader) (ModelMetadata, error) {
+	var hparams struct {
+		NVocab      int32
+		NAudioCtx   int32
+		NAudioState int32
+		NAudioHead  int32
+		NAudioLayer int32
+		NTextCtx    int32
+		NTextState  int32
+		NTextHead   int32
+		NTextLayer  int32
+		NMels       int32
+		FType       int32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &hparams); err != nil {
+		return ModelMetadata{}, fmt.Errorf("truncated ggml header: %w", err)
+	}
+
+	meta := ModelMetadata{
+		Format:       "ggml",
+		ModelType:    "unknown",
+		VocabSize:    int(hparams.NVocab),
+		Multilingual: hparams.NVocab >= multilingualVocabSize,
+		AudioLayers:  int(hparams.NAudioLayer),
+		TextLayers:   int(hparams.NTextLayer),
+	}
+	if t, ok := modelTypesByLayers[meta.AudioLayers]; ok {
+		meta.ModelType = t
+	}
+	return meta, nil
+}
+
+// GGUF metadata value types
+const (
+	ggufUint8 = iota
+	ggufInt8
+	ggufUint16
+	ggufInt16
+	ggufUint32
+	ggufInt32
+	ggufFloat32
+	ggufBool
+	ggufString
+	ggufArray
+	ggufUint64
+	ggufInt64
+	ggufFloat64
+)
+
+// maxGGUFString guards against allocating huge buffers for corrupt headers.
+const maxGGUFString = 1 << 20
+
+// readGGUFHeader walks the GGUF key/value metadata looking for the
+// architecture, layer counts and vocabulary size.
+func readGGUFHeader(r io.Reader) (ModelMetadata, error) {
+	var header struct {
+		Version     uint32
+		TensorCount uint64
+		KVCount     uint64
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return ModelMetadata{}, fmt.Errorf("truncated gguf header: %w", err)
+	}
+
+	meta := ModelMetadata{Format: "gguf", ModelType: "unknown"}
+	values := make(map[string]uint64)
+	arch := ""
+
+	for i := uint64(0); i < header.KVCount; i++ {
+		key, err := readGGUFString(r)
+		if err != nil {
+			return meta, fmt.Errorf("malformed gguf metadata: %w", err)
+		}
+		var typ uint32
+		if err := binary.Read(r, binary.LittleEndian, &typ); err != nil {
+			return meta, fmt.Errorf("malformed gguf metadata: %w", err)
+		}
+
+		switch {
+		case typ == ggufString:
+			s, err := readGGUFString(r)
+			if err != nil {
+				return meta, fmt.Errorf("malformed gguf metadata: %w", err)
+			}
+			if key == "general.architecture" {
+				arch = s
+			}
+		case typ == ggufArray && key == "tokenizer.ggml.tokens":
+			var elemType uint32
+			var count uint64
+			if err := binary.Read(r, binary.LittleEndian, &elemType); err != nil {
+				return meta, fmt.Errorf("malformed gguf metadata: %w", err)
+			}
+			if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+				return meta, fmt.Errorf("malformed gguf metadata: %w", err)
+			}
+			values["vocab_size"] = count
+			if err := skipGGUFValues(r, elemType, count); err != nil {
+				return meta, fmt.Errorf("malformed gguf metadata: %w", err)
+			}
+		default:
+			v, err := readGGUFScalar(r, typ)
+			if err != nil {
+				return meta, fmt.Errorf("malformed gguf metadata: %w", err)
+			}
+			values[key] = v
+		}
+	}
+
+	if v, ok := values[arch+".vocab_size"]; ok {
+		meta.VocabSize = int(v)
+	} else {
+		meta.VocabSize = int(values["vocab_size"])
+	}
+	meta.AudioLayers = int(values[arch+".encoder.block_count"])
+	meta.TextLayers = int(values[arch+".decoder.block_count"])
+	if meta.AudioLayers == 0 {
+		meta.AudioLayers = int(values[arch+".block_count"])
+	}
+	meta.Multilingual = meta.VocabSize >= multilingualVocabSize
+	if t, ok := modelTypesByLayers[meta.AudioLayers]; ok {
+		meta.ModelType = t
+	}
+	return meta, nil
+}
+
+func readGGUFString(r io.Reader) (string, error) {
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return "", err
+	}
+	if n > maxGGUFString {
+		return "", fmt.Errorf("string length %d too large", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// readGGUFScalar reads a value of type typ, returning integers as uint64.
+// Non-integer values are consumed and reported as zero.
+func readGGUFScalar(r io.Reader, typ uint32) (uint64, error) {
+	switch typ {
+	case ggufUint8, ggufInt8, ggufBool:
+		var v uint8
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case ggufUint16, ggufInt16:
+		var v uint16
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case ggufUint32, ggufInt32:
+		var v uint32
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case ggufFloat32:
+		var v float32
+		return 0, binary.Read(r, binary.LittleEndian, &v)
+	case ggufUint64, ggufInt64:
+		var v uint64
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case ggufFloat64:
+		var v float64
+		return 0, binary.Read(r, binary.LittleEndian, &v)
+	case ggufString:
+		_, err := readGGUFString(r)
+		return 0, err
+	case ggufArray:
+		var elemType uint32
+		var count uint64
+		if err := binary.Read(r, binary.LittleEndian, &elemType); err != nil {
+			return 0, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+			return 0, err
+		}
+		return 0, skipGGUFValues(r, elemType, count)
+	default:
+		return 0, fmt.Errorf("unknown value type %d", typ)
+	}
+}
+
+func skipGGUFValues(r io.Reader, typ uint32, count uint64) error {
+	for i := uint64(0); i < count; i++ {
+		if _, err := readGGUFScalar(r, typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkModelLanguage returns an error when an English-only model is used
+// for another language. Unreadable headers are left for whisper to report.
+func checkModelLanguage(modelPath, language string) error {
+	if language == "en" || language == "auto" {
+		return nil
+	}
+	meta, err := readModelMetadata(modelPath)
+	if err != nil || meta.Multilingual {
+		return nil
+	}
+	return fmt.Errorf("model %s is English-only and can't transcribe language %q; choose a multilingual model", filepath.Base(modelPath), language)
+}
+
+// ErrModelFormatMismatch is returned when the model's file format can't be
+// loaded by the installed whisper binary.
+var ErrModelFormatMismatch = errors.New("model format not supported by whisper")
+
+// checkModelFormat compares the model's header format with what the whisper
+// binary reads, so an incompatible model fails with guidance instead of
+// whisper's load error. whisper.cpp reads ggml models; gguf is only accepted
+// from builds whose help output mentions it. Unreadable headers are left
+// for whisper to report.
+func (t *TranscribeService) checkModelFormat(modelPath string) error {
+	meta, err := readModelMetadata(modelPath)
+	if err != nil || t.binaryReadsModelFormat(meta.Format) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is a %s model, but the installed %s only reads ggml models; download the ggml (.bin) version of the model or update whisper-cpp",
+		ErrModelFormatMismatch, filepath.Base(modelPath), meta.Format, t.whisperVariant)
+}
+
+// binaryReadsModelFormat reports whether the whisper binary can load models
+// in format ("ggml" or "gguf").
+func (t *TranscribeService) binaryReadsModelFormat(format string) bool {
+	if format != "gguf" {
+		return true
+	}
+	if t.whisperVariant == whisperVariantLegacy {
+		return false
+	}
+	t.probeHelp()
+	return strings.Contains(strings.ToLower(t.helpText), "gguf")
+}
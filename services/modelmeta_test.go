@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeModelFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.bin")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func ggmlHeader(vocab, audioLayers, textLayers int32) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(ggmlMagic))
+	// n_vocab, then audio ctx/state/head/layer, text ctx/state/head/layer,
+	// n_mels and ftype
+	for _, v := range []int32{vocab, 1500, 512, 8, audioLayers, 448, 512, 8, textLayers, 80, 1} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	return buf.Bytes()
+}
+
+func ggufHeader(kvs ...func(*bytes.Buffer)) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(ggufMagic))
+	binary.Write(&buf, binary.LittleEndian, uint32(3))        // version
+	binary.Write(&buf, binary.LittleEndian, uint64(0))        // tensor count
+	binary.Write(&buf, binary.LittleEndian, uint64(len(kvs))) // kv count
+	for _, kv := range kvs {
+		kv(&buf)
+	}
+	return buf.Bytes()
+}
+
+func writeGGUFString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.LittleEndian, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+func ggufStringKV(key, value string) func(*bytes.Buffer) {
+	return func(buf *bytes.Buffer) {
+		writeGGUFString(buf, key)
+		binary.Write(buf, binary.LittleEndian, uint32(ggufString))
+		writeGGUFString(buf, value)
+	}
+}
+
+func ggufUint32KV(key string, value uint32) func(*bytes.Buffer) {
+	return func(buf *bytes.Buffer) {
+		writeGGUFString(buf, key)
+		binary.Write(buf, binary.LittleEndian, uint32(ggufUint32))
+		binary.Write(buf, binary.LittleEndian, value)
+	}
+}
+
+func TestReadModelMetadataGGML(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want ModelMetadata
+	}{
+		{
+			name: "multilingual base",
+			data: ggmlHeader(51865, 6, 6),
+			want: ModelMetadata{Format: "ggml", ModelType: "base", VocabSize: 51865, Multilingual: true, AudioLayers: 6, TextLayers: 6},
+		},
+		{
+			name: "english-only small",
+			data: ggmlHeader(51864, 12, 12),
+			want: ModelMetadata{Format: "ggml", ModelType: "small", VocabSize: 51864, AudioLayers: 12, TextLayers: 12},
+		},
+		{
+			name: "unknown layer count",
+			data: ggmlHeader(51866, 7, 7),
+			want: ModelMetadata{Format: "ggml", ModelType: "unknown", VocabSize: 51866, Multilingual: true, AudioLayers: 7, TextLayers: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readModelMetadata(writeModelFile(t, tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("readModelMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadModelMetadataGGUF(t *testing.T) {
+	data := ggufHeader(
+		ggufStringKV("general.architecture", "whisper"),
+		ggufUint32KV("whisper.vocab_size", 51866),
+		ggufUint32KV("whisper.encoder.block_count", 32),
+		ggufUint32KV("whisper.decoder.block_count", 32),
+	)
+	got, err := readModelMetadata(writeModelFile(t, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ModelMetadata{Format: "gguf", ModelType: "large", VocabSize: 51866, Multilingual: true, AudioLayers: 32, TextLayers: 32}
+	if got != want {
+		t.Errorf("readModelMetadata() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadModelMetadataRejectsBadHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"empty", nil, "too short"},
+		{"wrong magic", []byte("RIFF\x00\x00\x00\x00"), "unrecognized model format"},
+		{"truncated ggml", ggmlHeader(51865, 6, 6)[:20], "truncated ggml header"},
+		{"truncated gguf", ggufHeader()[:10], "truncated gguf header"},
+		{"gguf string too long", ggufHeader(func(buf *bytes.Buffer) {
+			binary.Write(buf, binary.LittleEndian, uint64(maxGGUFString+1))
+		}), "malformed gguf metadata"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readModelMetadata(writeModelFile(t, tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readModelMetadata() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
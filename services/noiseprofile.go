@@ -0,0 +1,159 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+const (
+	minNoiseProfileSeconds = 1
+	maxNoiseProfileSeconds = 30
+)
+
+// noiseProfile is the room noise measured by CaptureNoiseProfile.
+type noiseProfile struct {
+	rms        float64   // overall int16 RMS
+	bands      []float64 // per-band magnitude floor, as from bandMagnitudes
+	sampleRate float64
+	maxFreq    float64 // spectrum ceiling the bands were measured with
+}
+
+// CaptureNoiseProfile records a few seconds of room noise, with nobody
+// speaking, and measures its level overall and per spectrum band. Speech
+// detection (the inactivity reminder and auto-stop) then only counts sound
+// above that floor, and the spectrum display subtracts it. If the clip
+// sounds like speech, the profile is still stored but an "audio:warning" is
+// emitted suggesting a retry. Can only be used while not recording.
+func (a *AudioService) CaptureNoiseProfile(seconds float64) error {
+	if seconds < minNoiseProfileSeconds || seconds > maxNoiseProfileSeconds {
+		return fmt.Errorf("noise profile length must be between %d and %d seconds", minNoiseProfileSeconds, maxNoiseProfileSeconds)
+	}
+
+	clip, sr, maxFreq, err := a.captureClip(seconds, "capture a noise profile")
+	if err != nil {
+		return err
+	}
+	if len(clip) < bufferSize {
+		return fmt.Errorf("no audio was captured for the noise profile")
+	}
+
+	// The transform is slow, so it runs before taking the lock
+	profile := measureNoise(clip, sr, maxFreq)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.noiseProfile = profile
+
+	if speech := speechInNoise(clip, profile.rms); speech {
+		msg := "the noise profile clip seems to contain speech; capture it again while the room is quiet"
+		log.Print(msg)
+		go application.Get().Event.Emit("audio:warning", AudioWarning{Message: msg})
+	}
+	return nil
+}
+
+// captureClip records seconds of mono audio from the input device without
+// touching the recording, for measurements like CaptureNoiseProfile. what
+// describes the caller for errors, e.g. "capture a noise profile". Also
+// returns the sample rate and spectrum ceiling in effect.
+func (a *AudioService) captureClip(seconds float64, what string) (clip []int16, sr, maxFreq float64, err error) {
+	a.mu.Lock()
+	if a.state != stateIdle {
+		a.mu.Unlock()
+		return nil, 0, 0, fmt.Errorf("cannot %s while %s", what, a.state)
+	}
+	if status, _ := micPermissionStatus(); status == micPermissionDenied || status == micPermissionRestricted {
+		a.mu.Unlock()
+		return nil, 0, 0, fmt.Errorf("cannot %s: microphone access is %s", what, status)
+	}
+	dev, err := a.resolveInputDevice()
+	if err != nil {
+		a.mu.Unlock()
+		return nil, 0, 0, err
+	}
+	sr = dev.DefaultSampleRate
+	numChannels := recordingChannels(dev, a.recChannels)
+	maxFreq = a.spectrumCeiling()
+
+	// The state keeps recordings from starting while the clip is captured
+	stream, _, err := openInputStream(dev, numChannels, !a.noDither, func(in []int16) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		clip = append(clip, mixToMono(in, numChannels)...)
+	})
+	if err != nil {
+		a.mu.Unlock()
+		return nil, 0, 0, fmt.Errorf("failed to open audio stream: %w", err)
+	}
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		a.mu.Unlock()
+		return nil, 0, 0, fmt.Errorf("failed to start audio stream: %w", err)
+	}
+	a.state = stateCalibrating
+	a.mu.Unlock()
+
+	time.Sleep(time.Duration(seconds * float64(time.Second)))
+	stopErr := stopStream(stream, streamStopTimeout)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.state = stateIdle
+	if stopErr != nil {
+		return nil, 0, 0, fmt.Errorf("failed to stop audio stream: %w", stopErr)
+	}
+	return clip, sr, maxFreq, nil
+}
+
+// ClearNoiseProfile discards the profile from CaptureNoiseProfile.
+func (a *AudioService) ClearNoiseProfile() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.noiseProfile = nil
+}
+
+// measureNoise averages the spectrum bands of clip over bufferSize frames.
+func measureNoise(clip []int16, sr, maxFreq float64) *noiseProfile {
+	p := &noiseProfile{
+		rms:        rms(clip),
+		bands:      make([]float64, spectrumBands),
+		sampleRate: sr,
+		maxFreq:    maxFreq,
+	}
+	frames := 0
+	for i := 0; i+bufferSize <= len(clip); i += bufferSize {
+		for b, v := range bandMagnitudes(clip[i:i+bufferSize], sr, maxFreq) {
+			p.bands[b] += v
+		}
+		frames++
+	}
+	for b := range p.bands {
+		p.bands[b] /= float64(frames)
+	}
+	return p
+}
+
+// speechInNoise reports whether any frame of clip is loud enough, relative
+// to the clip's overall level, to be speech rather than steady room noise.
+func speechInNoise(clip []int16, overall float64) bool {
+	for i := 0; i+bufferSize <= len(clip); i += bufferSize {
+		if r := rms(clip[i : i+bufferSize]); r >= speechRMSThreshold && r >= overall*2 {
+			return true
+		}
+	}
+	return false
+}
+
+// speechLevel returns how far the RMS of in rises above the noise profile,
+// subtracting the noise energy, or the plain RMS without a profile.
+// Callers must hold a.mu.
+func (a *AudioService) speechLevel(in []int16) float64 {
+	level := rms(in)
+	if a.noiseProfile == nil {
+		return level
+	}
+	return math.Sqrt(max(level*level-a.noiseProfile.rms*a.noiseProfile.rms, 0))
+}
@@ -0,0 +1,25 @@
+package services
+
+// Microphone permission states reported by CheckMicrophonePermission
+const (
+	micPermissionGranted      = "granted"
+	micPermissionDenied       = "denied"
+	micPermissionUndetermined = "undetermined"
+	micPermissionRestricted   = "restricted"
+)
+
+// CheckMicrophonePermission returns "granted", "denied", "undetermined" or
+// "restricted". Without permission macOS records silence rather than
+// failing, so the UI should check this and point the user to System
+// Settings > Privacy & Security > Microphone when it's denied.
+func (a *AudioService) CheckMicrophonePermission() (string, error) {
+	return micPermissionStatus()
+}
+
+// RequestMicrophonePermission shows the system permission prompt if the user
+// hasn't been asked yet, waits for their answer and returns the new status.
+// Once denied, macOS doesn't prompt again; the user has to change it in
+// System Settings.
+func (a *AudioService) RequestMicrophonePermission() (string, error) {
+	return requestMicPermission()
+}
@@ -0,0 +1,42 @@
+//go:build darwin
+
+package services
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AVFoundation -framework Foundation
+
+#import <AVFoundation/AVFoundation.h>
+
+static int micAuthorizationStatus(void) {
+	return (int)[AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio];
+}
+
+// requestMicAccess shows the system prompt if the user hasn't decided yet
+// and blocks until they answer.
+static void requestMicAccess(void) {
+	dispatch_semaphore_t done = dispatch_semaphore_create(0);
+	[AVCaptureDevice requestAccessForMediaType:AVMediaTypeAudio completionHandler:^(BOOL granted) {
+		dispatch_semaphore_signal(done);
+	}];
+	dispatch_semaphore_wait(done, DISPATCH_TIME_FOREVER);
+}
+*/
+import "C"
+
+// AVAuthorizationStatus values
+var micPermissionNames = map[C.int]string{
+	0: micPermissionUndetermined,
+	1: micPermissionRestricted,
+	2: micPermissionDenied,
+	3: micPermissionGranted,
+}
+
+func micPermissionStatus() (string, error) {
+	return micPermissionNames[C.micAuthorizationStatus()], nil
+}
+
+func requestMicPermission() (string, error) {
+	C.requestMicAccess()
+	return micPermissionStatus()
+}
@@ -0,0 +1,12 @@
+//go:build !darwin
+
+package services
+
+// Other platforms have no app-level microphone permission to query.
+func micPermissionStatus() (string, error) {
+	return micPermissionGranted, nil
+}
+
+func requestMicPermission() (string, error) {
+	return micPermissionGranted, nil
+}
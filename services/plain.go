@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SetOutputPlain also saves each transcript as a .txt holding only the
+// transcript text, with no heading, date or front matter, for tools that
+// don't understand markdown.
+func (t *TranscribeService) SetOutputPlain(enabled bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !enabled && t.cfg.noMarkdown {
+		return fmt.Errorf("markdown output is off; enable it before turning off plain text")
+	}
+	t.cfg.plainOutput = enabled
+	return nil
+}
+
+// SetOutputMarkdown turns the markdown file on or off. It can only be off
+// while plain text output is on, in which case TranscribeToFile returns the
+// .txt path instead.
+func (t *TranscribeService) SetOutputMarkdown(enabled bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !enabled && !t.cfg.plainOutput {
+		return fmt.Errorf("plain text output is off; enable it before turning off markdown")
+	}
+	t.cfg.noMarkdown = !enabled
+	return nil
+}
+
+// writePlainText writes text as is, adding only a final newline if missing.
+func writePlainText(path, text string) error {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return fmt.Errorf("failed to write transcription file: %w", err)
+	}
+	return nil
+}
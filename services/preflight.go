@@ -0,0 +1,50 @@
+package services
+
+import "fmt"
+
+const (
+	minPreflightSeconds = 0.5
+	maxPreflightSeconds = 10.0
+	// preflightSignalDb is the peak level below which the input is taken
+	// to be muted or dead; even a quiet room reads well above it on a
+	// working mic
+	preflightSignalDb = -60.0
+)
+
+// PreflightResult is the outcome of PreflightInput.
+type PreflightResult struct {
+	SignalDetected bool    `json:"signalDetected"`
+	PeakDb         float64 `json:"peakDb"` // dBFS
+	RMSDb          float64 `json:"rmsDb"`  // dBFS
+	Message        string  `json:"message"`
+}
+
+// PreflightInput briefly captures from the input device and reports whether
+// it's delivering a live signal, so a muted or dead mic is caught before a
+// meeting rather than after. Nothing is recorded, and the device is released
+// afterwards. Can only be used while not recording.
+func (a *AudioService) PreflightInput(seconds float64) (PreflightResult, error) {
+	var result PreflightResult
+	if seconds < minPreflightSeconds || seconds > maxPreflightSeconds {
+		return result, fmt.Errorf("pre-flight check length must be between %g and %g seconds", minPreflightSeconds, maxPreflightSeconds)
+	}
+
+	clip, _, _, err := a.captureClip(seconds, "check the input")
+	if err != nil {
+		return result, err
+	}
+
+	peak := 0
+	for _, s := range clip {
+		peak = max(peak, abs(int(s)))
+	}
+	result.PeakDb = toDbFS(float64(peak) / int16FullScale)
+	result.RMSDb = toDbFS(rms(clip) / int16FullScale)
+	result.SignalDetected = result.PeakDb >= preflightSignalDb
+	if result.SignalDetected {
+		result.Message = "Mic OK"
+	} else {
+		result.Message = "No signal detected; check that your microphone is connected and not muted"
+	}
+	return result, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// recordingProfile bundles the lower-level recording options.
+type recordingProfile struct {
+	channels  int
+	archiveSR int
+	highPass  bool
+	normalize bool
+	// telephoneBand is never part of a profile; it's listed so that turning
+	// it on shows up as "custom"
+	telephoneBand bool
+}
+
+var recordingProfiles = map[string]recordingProfile{
+	// Smallest files tuned for transcription accuracy
+	"whisper": {channels: 1, archiveSR: outputSampleRate, highPass: true, normalize: true},
+	// Native-rate mono with rumble removed
+	"balanced": {channels: 1, archiveSR: 0, highPass: true, normalize: false},
+	// Untouched stereo at the device's native rate
+	"archive": {channels: 2, archiveSR: 0, highPass: false, normalize: false},
+}
+
+// SetRecordingProfile applies a named bundle of recording options:
+// "whisper", "balanced" or "archive".
+func (a *AudioService) SetRecordingProfile(profile string) error {
+	p, ok := recordingProfiles[profile]
+	if !ok {
+		return fmt.Errorf("unknown recording profile: %s", profile)
+	}
+
+	if err := a.SetChannels(p.channels); err != nil {
+		return err
+	}
+	if err := a.SetArchiveSampleRate(p.archiveSR); err != nil {
+		return err
+	}
+	a.SetHighPassFilter(p.highPass)
+	a.SetNormalize(p.normalize)
+	a.SetTelephoneBandpass(p.telephoneBand)
+	return nil
+}
+
+// GetRecordingProfile returns the profile matching the current options, or
+// "custom" if they were changed individually.
+func (a *AudioService) GetRecordingProfile() string {
+	a.mu.Lock()
+	current := recordingProfile{
+		channels:  max(a.recChannels, channels),
+		archiveSR: a.archiveSR,
+		highPass:  a.highPass,
+		normalize: a.normalize,
+
+		telephoneBand: a.telephoneBand,
+	}
+	a.mu.Unlock()
+
+	for name, p := range recordingProfiles {
+		if p == current {
+			return name
+		}
+	}
+	return "custom"
+}
+
+// SetChannels sets the number of channels recorded (1 or 2), taking effect
+// on the next recording. Devices without enough inputs record mono.
+// The transcription WAV is always mixed down to mono.
+func (a *AudioService) SetChannels(n int) error {
+	if n != 1 && n != 2 {
+		return fmt.Errorf("channels must be 1 or 2, got %d", n)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.recChannels = n
+	return nil
+}
+
+// SetArchiveSampleRate sets the sample rate used by ExportAudio. Zero keeps
+// the device's native rate.
+func (a *AudioService) SetArchiveSampleRate(sr int) error {
+	if sr != 0 && (sr < 8000 || sr > 192000) {
+		return fmt.Errorf("archive sample rate must be between 8000 and 192000 Hz, got %d", sr)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.archiveSR = sr
+	return nil
+}
+
+// transcriptionSampleRates are the rates accepted for the transcription WAV.
+// Stock whisper.cpp only accepts 16kHz; the others exist for custom builds.
+var transcriptionSampleRates = []int{8000, 16000, 22050, 24000, 32000, 44100, 48000}
+
+// SetTranscriptionSampleRate changes the sample rate of the WAV written for
+// whisper. Only change this for whisper builds that expect a different rate.
+func (a *AudioService) SetTranscriptionSampleRate(sr int) error {
+	if !slices.Contains(transcriptionSampleRates, sr) {
+		return fmt.Errorf("unsupported transcription sample rate: %d", sr)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.transcriptionSR = sr
+	return nil
+}
+
+// SetResampleFilterTaps switches the transcription and export resampler to a
+// windowed-sinc filter n input samples long, or back to fast linear
+// interpolation with 0. n must be odd and between 7 and 511. More taps give
+// sharper anti-aliasing at a cost that grows linearly: saving an hour of
+// 48kHz audio takes a few seconds at 31 taps but about half a minute at 255.
+func (a *AudioService) SetResampleFilterTaps(n int) error {
+	if n != 0 && (n < minResampleTaps || n > maxResampleTaps || n%2 == 0) {
+		return fmt.Errorf("resample filter taps must be 0 or an odd number between %d and %d, got %d", minResampleTaps, maxResampleTaps, n)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.resampleTaps = n
+	return nil
+}
+
+// SetHighPassFilter toggles an 80Hz high-pass on the transcription WAV to
+// remove rumble and handling noise.
+func (a *AudioService) SetHighPassFilter(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.highPass = enabled
+}
+
+// SetNormalize toggles peak normalization of the transcription WAV, which
+// helps whisper with quiet recordings.
+func (a *AudioService) SetNormalize(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.normalize = enabled
+}
+
+// SetTelephoneBandpass toggles an experimental 300-3400Hz band-pass on the
+// transcription WAV, which can reduce noise-driven errors on conference-call
+// audio that is already band-limited. Off by default: on full-bandwidth
+// recordings it removes detail whisper uses and may hurt accuracy.
+func (a *AudioService) SetTelephoneBandpass(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.telephoneBand = enabled
+}
+
+// maxSourceGainDb bounds SetMicGain and SetSystemGain in both directions.
+const maxSourceGainDb = 24.0
+
+// SetMicGain adjusts the microphone level in the transcription WAV by db
+// decibels (0 by default), clamping rather than wrapping on clipping. With
+// SelectSystemAudioDevice it's applied before the system audio is mixed in;
+// see SetSystemGain.
+func (a *AudioService) SetMicGain(db float64) error {
+	if math.IsNaN(db) || db < -maxSourceGainDb || db > maxSourceGainDb {
+		return fmt.Errorf("mic gain must be between -%.0f and %.0f dB", maxSourceGainDb, maxSourceGainDb)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.micGainDb = db
+	return nil
+}
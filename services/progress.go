@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// TranscribeProgress is emitted as "transcribe:progress" while whisper runs.
+type TranscribeProgress struct {
+	RecordingID string  `json:"recordingId,omitempty"`
+	WavPath     string  `json:"wavPath"`
+	Percent     float64 `json:"percent"`
+}
+
+var (
+	// --print-progress lines, e.g.
+	// "whisper_print_progress_callback: progress =  42%"
+	progressPercentPattern = regexp.MustCompile(`progress\s*=\s*(\d+)%`)
+	// Segment lines, e.g. "[00:01:02.500 --> 00:01:05.000]  text"
+	progressSegmentPattern = regexp.MustCompile(`^\[\d+:\d\d:\d\d\.\d+ --> (\d+):(\d\d):(\d\d)\.(\d+)\]`)
+)
+
+// parsePercentProgress reads a --print-progress line.
+func parsePercentProgress(line string) (float64, bool) {
+	m := progressPercentPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	pct, _ := strconv.ParseFloat(m[1], 64)
+	return min(pct, 100), true
+}
+
+// parseSegmentProgress estimates progress from a segment line as the
+// segment's end time over the audio duration in seconds.
+func parseSegmentProgress(line string, duration float64) (float64, bool) {
+	m := progressSegmentPattern.FindStringSubmatch(line)
+	if m == nil || duration <= 0 {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[2])
+	s, _ := strconv.Atoi(m[3])
+	frac, _ := strconv.ParseFloat("0."+m[4], 64)
+	end := float64(h*3600+mins*60+s) + frac
+	return min(end/duration*100, 100), true
+}
+
+// progressReporter returns a line handler for a whisper run on wavPath that
+// emits "transcribe:progress" each time the whole percentage advances.
+// Binaries with --print-progress report percentages directly; otherwise
+// progress is estimated from segment timestamps and the WAV's duration.
+func (t *TranscribeService) progressReporter(wavPath, audioPath string, printProgress bool) func(string) {
+	duration, _ := wavDuration(audioPath)
+	id := recordings.idForPath(wavPath)
+	last := -1.0
+	return func(line string) {
+		var pct float64
+		var ok bool
+		if printProgress {
+			pct, ok = parsePercentProgress(line)
+		} else {
+			pct, ok = parseSegmentProgress(line, duration)
+		}
+		if !ok || float64(int(pct)) <= last {
+			return
+		}
+		last = float64(int(pct))
+		application.Get().Event.Emit("transcribe:progress", TranscribeProgress{
+			RecordingID: id,
+			WavPath:     wavPath,
+			Percent:     last,
+		})
+	}
+}
+
+// lineWriter collects a command's output while passing each complete line,
+// split on LF or CR, to onLine as it arrives.
+type lineWriter struct {
+	output  bytes.Buffer
+	partial []byte
+	onLine  func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	if w.onLine == nil {
+		return len(p), nil
+	}
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexAny(w.partial, "\r\n")
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			w.onLine(string(w.partial[:i]))
+		}
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Progress verbosity levels for SetProgressVerbosity
+const (
+	progressOverall  = "overall"
+	progressDetailed = "detailed"
+)
+
+// JobProgress is emitted as "transcribe:job-progress" while a batch or
+// chunked transcription runs. Index, and for batches Path, are only set at
+// detailed verbosity. The last event of a job has Done set.
+type JobProgress struct {
+	Job     string  `json:"job"`             // "batch" or "chunked"
+	Index   int     `json:"index,omitempty"` // 1-based item that just finished
+	Total   int     `json:"total"`
+	Path    string  `json:"path,omitempty"` // the batch file, or the chunked WAV
+	Percent float64 `json:"percent"`
+	Done    bool    `json:"done"`
+}
+
+// SetProgressVerbosity chooses how batch and chunked jobs report progress:
+// "detailed" (the default) emits an event with its index for every file or
+// chunk, plus the per-file "transcribe:batch" results, while "overall"
+// emits only an aggregated percentage when it advances. Either way a final
+// event with Done set is sent when the job ends.
+func (t *TranscribeService) SetProgressVerbosity(level string) error {
+	switch level {
+	case progressOverall, progressDetailed:
+	default:
+		return fmt.Errorf("unknown progress verbosity %q (want overall or detailed)", level)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.progressVerbosity = level
+	return nil
+}
+
+func (c transcribeConfig) detailedProgress() bool {
+	return c.progressVerbosity != progressOverall
+}
+
+// jobReporter emits "transcribe:job-progress" for one job.
+type jobReporter struct {
+	job, path string
+	total     int
+	detailed  bool
+	last      float64
+}
+
+func (c transcribeConfig) newJobReporter(job, path string, total int) *jobReporter {
+	return &jobReporter{job: job, path: path, total: total, detailed: c.detailedProgress(), last: -1}
+}
+
+// finished reports that item index (0-based) at path is done.
+func (r *jobReporter) finished(index int, path string) {
+	pct := float64(int(float64(index+1) / float64(max(r.total, 1)) * 100))
+	if r.detailed {
+		r.emit(JobProgress{Index: index + 1, Path: path, Percent: pct})
+		return
+	}
+	if pct > r.last {
+		r.emit(JobProgress{Percent: pct})
+	}
+}
+
+// done sends the final event, whether or not every item succeeded.
+func (r *jobReporter) done() {
+	r.emit(JobProgress{Percent: max(r.last, 0), Done: true})
+}
+
+func (r *jobReporter) emit(p JobProgress) {
+	p.Job, p.Total = r.job, r.total
+	if p.Path == "" && r.path != "" {
+		p.Path = r.path
+	}
+	r.last = p.Percent
+	application.Get().Event.Emit("transcribe:job-progress", p)
+}
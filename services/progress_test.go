@@ -0,0 +1,47 @@
+package services
+
+import "testing"
+
+func TestParsePercentProgress(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   float64
+		wantOK bool
+	}{
+		{"whisper_print_progress_callback: progress =  42%", 42, true},
+		{"whisper_print_progress_callback: progress = 100%", 100, true},
+		{"progress=7%", 7, true},
+		{"progress = 250%", 100, true},
+		{"[00:00:01.000 --> 00:00:02.000]  hello", 0, false},
+		{"whisper_init_from_file: loading model", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePercentProgress(tt.line)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parsePercentProgress(%q) = %v, %v, want %v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseSegmentProgress(t *testing.T) {
+	tests := []struct {
+		line     string
+		duration float64
+		want     float64
+		wantOK   bool
+	}{
+		{"[00:00:00.000 --> 00:00:30.000]  hello", 120, 25, true},
+		{"[00:01:00.000 --> 00:01:30.500]  more", 181, 50, true},
+		{"[01:00:00.000 --> 01:00:00.000]  late", 7200, 50, true},
+		{"[00:00:00.000 --> 00:02:10.000]  past the end", 120, 100, true},
+		{"[00:00:00.000 --> 00:00:30.000]  no duration", 0, 0, false},
+		{"  [00:00:00.000 --> 00:00:30.000] indented", 120, 0, false},
+		{"whisper_print_progress_callback: progress =  42%", 120, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSegmentProgress(tt.line, tt.duration)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseSegmentProgress(%q, %v) = %v, %v, want %v, %v", tt.line, tt.duration, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
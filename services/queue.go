@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// DownloadQueue is the state of the download queue, emitted as
+// "model:download-queue" whenever it changes.
+type DownloadQueue struct {
+	Active  string   `json:"active,omitempty"` // model downloading now
+	Pending []string `json:"pending"`
+}
+
+// QueueDownloads adds models to the download queue, which downloads them one
+// at a time in order. Models already queued or downloading are skipped.
+// Progress for each is reported through "model:download-progress" as usual.
+func (m *ModelService) QueueDownloads(names []string) error {
+	for _, name := range names {
+		if _, ok := findModelDefinition(name); !ok {
+			return fmt.Errorf("unknown model: %s", name)
+		}
+	}
+
+	m.mu.Lock()
+	for _, name := range names {
+		if name != m.queueActive && !slices.Contains(m.queue, name) {
+			m.queue = append(m.queue, name)
+		}
+	}
+	start := !m.queueRunning && len(m.queue) > 0
+	if start {
+		m.queueRunning = true
+	}
+	m.mu.Unlock()
+
+	m.emitQueue()
+	if start {
+		go m.runQueue()
+	}
+	return nil
+}
+
+// GetDownloadQueue returns the active and pending queued downloads.
+func (m *ModelService) GetDownloadQueue() DownloadQueue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return DownloadQueue{Active: m.queueActive, Pending: append([]string{}, m.queue...)}
+}
+
+// SkipCurrentDownload pauses the queue's active download, keeping its
+// partial file, and moves on to the next model.
+func (m *ModelService) SkipCurrentDownload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cancel, ok := m.cancels[m.queueActive]
+	if m.queueActive == "" || !ok {
+		return fmt.Errorf("no queued download is active")
+	}
+	cancel(errDownloadPaused)
+	delete(m.cancels, m.queueActive)
+	return nil
+}
+
+// CancelQueuedDownload removes a model that hasn't started yet from the
+// download queue. The active download is left alone; use
+// SkipCurrentDownload for that.
+func (m *ModelService) CancelQueuedDownload(name string) error {
+	m.mu.Lock()
+	if name == m.queueActive {
+		m.mu.Unlock()
+		return fmt.Errorf("model %s is downloading now; use SkipCurrentDownload to skip it", name)
+	}
+	i := slices.Index(m.queue, name)
+	if i < 0 {
+		m.mu.Unlock()
+		return fmt.Errorf("model %s is not queued", name)
+	}
+	m.queue = slices.Delete(m.queue, i, i+1)
+	m.mu.Unlock()
+
+	m.emitQueue()
+	return nil
+}
+
+// clearQueue drops every pending queued download.
+func (m *ModelService) clearQueue() {
+	m.mu.Lock()
+	cleared := len(m.queue) > 0
+	m.queue = nil
+	m.mu.Unlock()
+	if cleared {
+		m.emitQueue()
+	}
+}
+
+// runQueue downloads queued models until the queue is empty.
+func (m *ModelService) runQueue() {
+	for {
+		m.mu.Lock()
+		if len(m.queue) == 0 {
+			m.queueActive = ""
+			m.queueRunning = false
+			m.mu.Unlock()
+			m.emitQueue()
+			return
+		}
+		name := m.queue[0]
+		m.queue = m.queue[1:]
+		m.queueActive = name
+		m.mu.Unlock()
+		m.emitQueue()
+
+		ctx, model, dir, err := m.beginDownload(name)
+		if err != nil {
+			log.Printf("skipping queued download: %v", err)
+			continue
+		}
+		m.doDownload(ctx, model, dir)
+	}
+}
+
+func (m *ModelService) emitQueue() {
+	application.Get().Event.Emit("model:download-queue", m.GetDownloadQueue())
+}
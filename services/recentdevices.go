@@ -0,0 +1,50 @@
+package services
+
+import (
+	"log"
+	"slices"
+)
+
+// maxRecentInputDevices is how many devices RecentInputDevices remembers.
+const maxRecentInputDevices = 5
+
+// RecentInputDevices returns the input devices most recently selected or
+// recorded from, newest first, for a quick-switch picker. Devices are
+// remembered by name, since indices change as devices come and go; ones not
+// currently connected are returned with Present false and Index -1. Like
+// GetSelectedInputDevice it doesn't refresh devices, so indices match the
+// last ListInputDevices.
+func (a *AudioService) RecentInputDevices() []InputDeviceInfo {
+	settings, err := loadSettings()
+	if err != nil {
+		log.Printf("failed to load recent input devices: %v", err)
+		return nil
+	}
+	a.mu.Lock()
+	current, _ := a.inputDevices()
+	a.mu.Unlock()
+
+	result := make([]InputDeviceInfo, 0, len(settings.RecentInputDevices))
+	for _, name := range settings.RecentInputDevices {
+		i := slices.IndexFunc(current, func(d InputDeviceInfo) bool { return d.Name == name })
+		if i >= 0 {
+			result = append(result, current[i])
+		} else {
+			result = append(result, InputDeviceInfo{Index: -1, Name: name})
+		}
+	}
+	return result
+}
+
+// rememberInputDevice moves name to the front of the persisted recent
+// devices. Errors are only logged, since the list is a convenience.
+func rememberInputDevice(name string) {
+	err := updateSettings(func(s *Settings) {
+		recent := slices.DeleteFunc(s.RecentInputDevices, func(n string) bool { return n == name })
+		recent = append([]string{name}, recent...)
+		s.RecentInputDevices = recent[:min(len(recent), maxRecentInputDevices)]
+	})
+	if err != nil {
+		log.Printf("failed to save recent input devices: %v", err)
+	}
+}
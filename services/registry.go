@@ -0,0 +1,200 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RecordingInfo ties together the files produced for one recording. The ID
+// is assigned by StartRecording and carried through events and sidecars.
+type RecordingInfo struct {
+	ID             string    `json:"id"`
+	StartedAt      time.Time `json:"startedAt"`
+	WavPath        string    `json:"wavPath,omitempty"`       // temp audio handed to transcription
+	NativeWavPath  string    `json:"nativeWavPath,omitempty"` // see SetKeepNativeRecording
+	MarkdownPath   string    `json:"markdownPath,omitempty"`
+	TranscriptPath string    `json:"transcriptPath,omitempty"`
+	SavedAudioPath string    `json:"savedAudioPath,omitempty"` // copy next to the markdown
+	AudioHash      string    `json:"audioHash,omitempty"`      // see sampleHash
+	Duration       float64   `json:"duration,omitempty"`       // seconds recorded, excluding pauses
+	// SegmentPaths lists the files of a recording split with
+	// SetSegmentOnResume, in order; WavPath is empty then
+	SegmentPaths []string `json:"segmentPaths,omitempty"`
+}
+
+// recordingRegistry maps recording IDs to their files for this session.
+type recordingRegistry struct {
+	mu   sync.Mutex
+	byID map[string]*RecordingInfo
+}
+
+var recordings recordingRegistry
+
+// newRecordingID returns a sortable ID made of the start time and a random
+// suffix, e.g. "20260102-150405-3f9a1c".
+func newRecordingID(start time.Time) string {
+	var b [3]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// Fall back to the clock so IDs stay unique within the session
+		return start.Format("20060102-150405.000000")
+	}
+	return start.Format("20060102-150405") + "-" + hex.EncodeToString(b[:])
+}
+
+func (r *recordingRegistry) add(info RecordingInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byID == nil {
+		r.byID = make(map[string]*RecordingInfo)
+	}
+	r.byID[info.ID] = &info
+}
+
+// update applies fn to the recording with the given ID, if it's known, and
+// saves it as the last recording so it can be reopened after a restart.
+func (r *recordingRegistry) update(id string, fn func(*RecordingInfo)) {
+	r.mu.Lock()
+	info, ok := r.byID[id]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	fn(info)
+	saved := *info
+	r.mu.Unlock()
+
+	err := updateSettings(func(s *Settings) {
+		// Don't let a late update to an older recording replace a newer one
+		if s.LastRecording == nil || !s.LastRecording.StartedAt.After(saved.StartedAt) {
+			s.LastRecording = &saved
+		}
+	})
+	if err != nil {
+		log.Printf("failed to save last recording: %v", err)
+	}
+}
+
+func (r *recordingRegistry) get(id string) (RecordingInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.byID[id]
+	if !ok {
+		return RecordingInfo{}, false
+	}
+	return *info, true
+}
+
+// idForPath returns the ID of the recording that produced path, or "".
+func (r *recordingRegistry) idForPath(path string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, info := range r.byID {
+		switch path {
+		case info.WavPath, info.MarkdownPath, info.TranscriptPath, info.SavedAudioPath:
+			return id
+		}
+		if slices.Contains(info.SegmentPaths, path) {
+			return id
+		}
+	}
+	return ""
+}
+
+// list returns all recordings, newest first.
+func (r *recordingRegistry) list() []RecordingInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]RecordingInfo, 0, len(r.byID))
+	for _, info := range r.byID {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt.After(result[j].StartedAt) })
+	return result
+}
+
+// GetRecording returns the files known for a recording made this session.
+func (a *AudioService) GetRecording(id string) (RecordingInfo, error) {
+	info, ok := recordings.get(id)
+	if !ok {
+		return RecordingInfo{}, fmt.Errorf("unknown recording: %s", id)
+	}
+	return info, nil
+}
+
+// ListRecordings returns the recordings made this session, newest first.
+func (a *AudioService) ListRecordings() []RecordingInfo {
+	return recordings.list()
+}
+
+// GetRecordingID returns the ID of the current or most recent recording,
+// or "" if nothing has been recorded yet.
+func (a *AudioService) GetRecordingID() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.recordingID
+}
+
+// ErrNoSession is returned by GetLastSession when there is no earlier
+// recording to reopen.
+var ErrNoSession = errors.New("no previous recording found")
+
+// SessionInfo describes the last recording for "Resume last meeting".
+type SessionInfo struct {
+	RecordingID   string  `json:"recordingId"`
+	WavPath       string  `json:"wavPath"`
+	Duration      float64 `json:"duration"` // seconds; 0 if unknown
+	HasTranscript bool    `json:"hasTranscript"`
+	MarkdownPath  string  `json:"markdownPath,omitempty"`
+}
+
+// GetLastSession returns the most recent recording, including ones from
+// before the app was restarted. The temp WAV is preferred; if the system
+// cleaned it up, the copy saved next to the transcript is used instead.
+func (a *AudioService) GetLastSession() (SessionInfo, error) {
+	settings, err := loadSettings()
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	last := settings.LastRecording
+	if last == nil {
+		return SessionInfo{}, ErrNoSession
+	}
+
+	session := SessionInfo{RecordingID: last.ID}
+	for _, p := range []string{last.WavPath, last.SavedAudioPath} {
+		if p == "" {
+			continue
+		}
+		if _, err := os.Stat(p); err == nil {
+			session.WavPath = p
+			break
+		}
+	}
+	if session.WavPath == "" {
+		return SessionInfo{}, fmt.Errorf("%w: the audio from the last recording was cleaned up", ErrNoSession)
+	}
+
+	if d, err := wavDuration(session.WavPath); err == nil {
+		session.Duration = d
+	}
+	if last.MarkdownPath != "" {
+		if _, err := os.Stat(last.MarkdownPath); err == nil {
+			session.HasTranscript = true
+			session.MarkdownPath = last.MarkdownPath
+		}
+	}
+
+	// Make the recording known again so its ID resolves in this session
+	if _, ok := recordings.get(last.ID); !ok {
+		recordings.add(*last)
+	}
+	return session, nil
+}
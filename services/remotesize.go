@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// remoteSizes holds exact model sizes fetched by RefreshCatalogSizes, keyed
+// by download URL so a catalog reload can't attach them to the wrong file.
+// Guarded by catalogMu.
+var remoteSizes = map[string]int64{}
+
+// RefreshCatalogSizes asks the server for the size of every catalog model
+// with a HEAD request, replacing the hand-maintained sizes in the catalog
+// with the real ones and setting each model's exact Bytes. Requests go
+// through the same client as downloads, so proxy settings and mirror URLs
+// from a custom catalog apply. Models whose request fails keep their
+// listed size; an error is only returned if none succeeded. CancelDownload
+// stops it.
+func (m *ModelService) RefreshCatalogSizes() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.mu.Lock()
+	if m.sizesCancel != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("catalog sizes are already being refreshed")
+	}
+	m.sizesCancel = cancel
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.sizesCancel = nil
+		m.mu.Unlock()
+	}()
+
+	connect, idle := m.downloadTimeouts()
+	client := downloadClient(connect, idle)
+	var lastErr error
+	refreshed := 0
+	for _, def := range modelCatalog() {
+		size, err := remoteSize(ctx, client, def.URL)
+		if ctx.Err() != nil {
+			return fmt.Errorf("size refresh cancelled")
+		}
+		if err != nil {
+			log.Printf("failed to fetch size of %s: %v", def.Name, err)
+			lastErr = err
+			continue
+		}
+		catalogMu.Lock()
+		remoteSizes[def.URL] = size
+		catalogMu.Unlock()
+		refreshed++
+	}
+	if refreshed == 0 && lastErr != nil {
+		return fmt.Errorf("failed to fetch model sizes: %w", lastErr)
+	}
+	return nil
+}
+
+// remoteSize returns the length of the file at url from a HEAD request.
+// Hugging Face also reports it as X-Linked-Size on the redirect to its CDN,
+// which is used if the final response has no length.
+func remoteSize(ctx context.Context, client *http.Client, url string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	if resp.ContentLength > 0 {
+		return resp.ContentLength, nil
+	}
+	for r := resp; r != nil; {
+		if n, err := strconv.ParseInt(r.Header.Get("X-Linked-Size"), 10, 64); err == nil && n > 0 {
+			return n, nil
+		}
+		if r.Request == nil {
+			break
+		}
+		r = r.Request.Response
+	}
+	return 0, errors.New("server did not report a size")
+}
+
+// modelBytes returns a model's size in bytes: the exact size if known,
+// otherwise parsed from its catalog size. 0 if neither is available.
+func modelBytes(def ModelInfo) int64 {
+	if def.Bytes > 0 {
+		return def.Bytes
+	}
+	n, _ := parseSize(def.Size)
+	return n
+}
+
+// formatSize renders bytes the way the catalog writes sizes, e.g. "142 MB"
+// or "3.1 GB", with binary units.
+func formatSize(n int64) string {
+	if n >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%.0f MB", float64(n)/(1<<20))
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ReplacementOptions controls how SetReplacements entries are matched.
+type ReplacementOptions struct {
+	CaseInsensitive bool `json:"caseInsensitive"`
+	// Regex treats keys as regular expressions instead of literal words.
+	// Off by default so a stray "." or "*" can't rewrite the whole transcript.
+	Regex bool `json:"regex"`
+}
+
+type replacement struct {
+	pattern *regexp.Regexp
+	with    string
+	// A match must start or end at a word boundary; see isWordRune
+	wordStart, wordEnd bool
+}
+
+// compileReplacements builds matchers for dict. Literal keys only match on
+// word boundaries where the key begins or ends with a word character, so
+// "Gym" doesn't rewrite "Gymnasium" nor "José" "Josée", while CJK keys
+// still match inside text.
+func compileReplacements(dict map[string]string, opts ReplacementOptions) ([]replacement, error) {
+	keys := make([]string, 0, len(dict))
+	for k := range dict {
+		if k == "" {
+			return nil, fmt.Errorf("replacement key cannot be empty")
+		}
+		keys = append(keys, k)
+	}
+	// Longest first so overlapping entries prefer the most specific match
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	result := make([]replacement, 0, len(keys))
+	for _, k := range keys {
+		expr := k
+		var wordStart, wordEnd bool
+		if !opts.Regex {
+			// regexp's \b only knows ASCII, so boundaries are checked
+			// around each match instead
+			expr = regexp.QuoteMeta(k)
+			first, _ := utf8.DecodeRuneInString(k)
+			last, _ := utf8.DecodeLastRuneInString(k)
+			wordStart, wordEnd = isWordRune(first), isWordRune(last)
+		}
+		if opts.CaseInsensitive {
+			expr = "(?i)" + expr
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replacement pattern %q: %w", k, err)
+		}
+
+		with := dict[k]
+		if !opts.Regex {
+			// Keep "$" in literal replacements from being read as a group reference
+			with = strings.ReplaceAll(with, "$", "$$")
+		}
+		result = append(result, replacement{pattern: re, with: with, wordStart: wordStart, wordEnd: wordEnd})
+	}
+	return result, nil
+}
+
+func applyReplacements(text string, reps []replacement) string {
+	for _, r := range reps {
+		text = r.apply(text)
+	}
+	return text
+}
+
+// apply replaces the matches of r in text that sit on the word boundaries
+// it requires.
+func (r replacement) apply(text string) string {
+	if !r.wordStart && !r.wordEnd {
+		return r.pattern.ReplaceAllString(text, r.with)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range r.pattern.FindAllStringSubmatchIndex(text, -1) {
+		if r.wordStart {
+			if before, _ := utf8.DecodeLastRuneInString(text[:m[0]]); isWordRune(before) {
+				continue
+			}
+		}
+		if r.wordEnd {
+			if after, _ := utf8.DecodeRuneInString(text[m[1]:]); isWordRune(after) {
+				continue
+			}
+		}
+		b.WriteString(text[last:m[0]])
+		b.Write(r.pattern.ExpandString(nil, r.with, text, m))
+		last = m[1]
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isWordRune reports whether r is part of a word for replacement
+// boundaries: a letter, digit, combining mark or underscore in any script
+// written with spaces between words. Han and kana aren't, so keys in them
+// match anywhere.
+func isWordRune(r rune) bool {
+	if r == utf8.RuneError || isCJK(r) {
+		return false
+	}
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
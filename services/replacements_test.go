@@ -0,0 +1,37 @@
+package services
+
+import "testing"
+
+func TestApplyReplacements(t *testing.T) {
+	tests := []struct {
+		name string
+		dict map[string]string
+		opts ReplacementOptions
+		text string
+		want string
+	}{
+		{"whole word", map[string]string{"Gym": "Jim"}, ReplacementOptions{}, "Gym went to the Gymnasium", "Jim went to the Gymnasium"},
+		{"accented end", map[string]string{"José": "Jose P."}, ReplacementOptions{}, "José and Josée met José", "Jose P. and Josée met Jose P."},
+		{"accented start", map[string]string{"Émile": "Emil"}, ReplacementOptions{}, "Émile, not CÉmile", "Emil, not CÉmile"},
+		{"inside a non-ascii word", map[string]string{"Café": "Cafe"}, ReplacementOptions{}, "Caféteria", "Caféteria"},
+		{"adjacent matches", map[string]string{"Café": "Cafe"}, ReplacementOptions{}, "Café Café", "Cafe Cafe"},
+		{"combining accent after", map[string]string{"Jose": "José"}, ReplacementOptions{}, "Jose\u0301 and Jose", "Jose\u0301 and José"},
+		{"punctuation key", map[string]string{"C++": "C plus plus"}, ReplacementOptions{}, "we use C++, not C", "we use C plus plus, not C"},
+		{"cjk matches inside text", map[string]string{"会議": "ミーティング"}, ReplacementOptions{}, "今日の会議です", "今日のミーティングです"},
+		{"cyrillic", map[string]string{"Миша": "Михаил"}, ReplacementOptions{}, "Миша и Мишами", "Михаил и Мишами"},
+		{"case insensitive", map[string]string{"josé": "José"}, ReplacementOptions{CaseInsensitive: true}, "JOSÉ said", "José said"},
+		{"dollar kept literal", map[string]string{"ten dollars": "$10"}, ReplacementOptions{}, "it was ten dollars", "it was $10"},
+		{"regex", map[string]string{`(\d+) pct`: "$1%"}, ReplacementOptions{Regex: true}, "up 5 pct", "up 5%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reps, err := compileReplacements(tt.dict, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := applyReplacements(tt.text, reps); got != tt.want {
+				t.Errorf("applyReplacements(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
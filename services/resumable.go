@@ -0,0 +1,65 @@
+package services
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResumableInfo is a partial download left in the models directory, which
+// DownloadModel will resume.
+type ResumableInfo struct {
+	ModelName   string `json:"modelName"`
+	BytesLoaded int64  `json:"bytesLoaded"`
+	BytesTotal  int64  `json:"bytesTotal"` // from the catalog size; 0 if unknown
+}
+
+// ResumableDownloads lists partial downloads of catalog models that aren't
+// currently downloading, e.g. for "Resume large-v3 (2.1/3.1 GB)".
+func (m *ModelService) ResumableDownloads() []ResumableInfo {
+	m.mu.Lock()
+	active := make(map[string]bool, len(m.progress))
+	for name := range m.progress {
+		active[name] = true
+	}
+	m.mu.Unlock()
+
+	dir := m.GetModelsDir()
+	resumable := []ResumableInfo{}
+	for _, def := range modelCatalog() {
+		if active[def.Name] {
+			continue
+		}
+		fi, err := os.Stat(filepath.Join(dir, def.FileName) + ".part")
+		if err != nil || fi.Size() == 0 {
+			continue
+		}
+		resumable = append(resumable, ResumableInfo{ModelName: def.Name, BytesLoaded: fi.Size(), BytesTotal: modelBytes(def)})
+	}
+	return resumable
+}
+
+// removeOrphanedParts deletes .part files in the models directory that
+// don't belong to any catalog model, so they can never be resumed.
+func removeOrphanedParts(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	known := make(map[string]bool)
+	for _, def := range modelCatalog() {
+		known[def.FileName] = true
+	}
+	for _, e := range entries {
+		file, ok := strings.CutSuffix(e.Name(), ".part")
+		if !ok || e.IsDir() || known[file] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			log.Printf("failed to remove orphaned partial download %s: %v", e.Name(), err)
+			continue
+		}
+		log.Printf("removed orphaned partial download %s", e.Name())
+	}
+}
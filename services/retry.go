@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+const (
+	maxTranscribeRetries = 5
+	// retryBackoff is multiplied by the attempt number between retries
+	retryBackoff = time.Second
+)
+
+// retryablePatterns appear in whisper output when a run failed for a
+// transient reason, typically the GPU being busy or short on memory.
+var retryablePatterns = []string{
+	"resource temporarily unavailable",
+	"device or resource busy",
+	"ggml_metal_init: error",
+	"mtlcommandbuffer",
+	"failed to allocate",
+	"cuda error: out of memory",
+}
+
+// fatalPatterns mean retrying can't help, even if a retryable pattern
+// also appears.
+var fatalPatterns = []string{
+	"failed to load model",
+	"failed to open",
+	"unknown argument",
+	"invalid model",
+	"error: input file not found",
+}
+
+// TranscribeRetry is emitted as "transcribe:retry" before whisper is re-run
+// after a transient failure.
+type TranscribeRetry struct {
+	Attempt    int    `json:"attempt"`
+	MaxRetries int    `json:"maxRetries"`
+	Reason     string `json:"reason"`
+}
+
+// SetTranscribeRetries sets how many times a whisper run is retried after a
+// transient failure such as the Metal device being busy. Errors like a
+// missing model or bad flags never retry. Zero (the default) disables it.
+func (t *TranscribeService) SetTranscribeRetries(n int) error {
+	if n < 0 || n > maxTranscribeRetries {
+		return fmt.Errorf("retries must be between 0 and %d, got %d", maxTranscribeRetries, n)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.retries = n
+	return nil
+}
+
+// retryableFailure classifies a failed run from its output, returning the
+// matched transient error or "" if the failure shouldn't be retried.
+func retryableFailure(output string) string {
+	output = strings.ToLower(output)
+	for _, p := range fatalPatterns {
+		if strings.Contains(output, p) {
+			return ""
+		}
+	}
+	for _, p := range retryablePatterns {
+		if strings.Contains(output, p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// runWhisper runs whisper with args, retrying transient failures up to
+// retries times. onLine, if set, sees each line of output as it's
+// printed. It returns the combined output of the last attempt.
+func (t *TranscribeService) runWhisper(ctx context.Context, args []string, retries int, onLine func(string)) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		cmd := exec.CommandContext(ctx, t.whisperBin, args...)
+		cmd.WaitDelay = whisperWaitDelay
+		w := &lineWriter{onLine: onLine}
+		cmd.Stdout = w
+		cmd.Stderr = w
+		err := cmd.Run()
+		output := w.output.Bytes()
+		t.whisperLog.set(output)
+		if err == nil || ctx.Err() != nil || attempt >= retries {
+			return output, err
+		}
+		reason := retryableFailure(string(output))
+		if reason == "" {
+			return output, err
+		}
+
+		application.Get().Event.Emit("transcribe:retry", TranscribeRetry{
+			Attempt:    attempt + 1,
+			MaxRetries: retries,
+			Reason:     reason,
+		})
+		select {
+		case <-ctx.Done():
+			return output, err
+		case <-time.After(retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import "testing"
+
+func TestRetryableFailure(t *testing.T) {
+	tests := []struct {
+		name, output, want string
+	}{
+		{"metal busy", "ggml_metal_init: error: device busy", "ggml_metal_init: error"},
+		{"case insensitive", "MTLCommandBuffer execution failed", "mtlcommandbuffer"},
+		{"cuda oom", "CUDA error: out of memory\ncurrent device: 0", "cuda error: out of memory"},
+		{"allocation", "whisper_init_state: failed to allocate memory for kv cache", "failed to allocate"},
+		{"eagain", "read: Resource temporarily unavailable", "resource temporarily unavailable"},
+		{"missing model", "whisper_init_from_file: failed to load model", ""},
+		{"bad flag", "error: unknown argument: --foo", ""},
+		{"missing input", "error: input file not found 'x.wav'", ""},
+		{"fatal wins over transient", "failed to allocate buffer\nfailed to load model", ""},
+		{"unrelated failure", "segmentation fault", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableFailure(tt.output); got != tt.want {
+				t.Errorf("retryableFailure(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
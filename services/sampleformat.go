@@ -0,0 +1,73 @@
+package services
+
+import (
+	"math"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// Input sample formats negotiated with the device
+const (
+	sampleFormatInt16   = "int16"
+	sampleFormatFloat32 = "float32" // converted to int16 as it arrives
+)
+
+// GetSampleFormat returns the sample format negotiated with the input
+// device for the current or last recording: "int16", or "float32" for
+// interfaces that don't offer 16-bit capture. Returns "" before the first
+// recording.
+func (a *AudioService) GetSampleFormat() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.sampleFormat
+}
+
+// openInputStream opens a capture stream on dev delivering int16 samples to
+// callback. Devices that only offer float32 are opened in that format and
+// converted, with TPDF dither if dither is set, so callers always see
+// int16. Returns the negotiated format.
+func openInputStream(dev *portaudio.DeviceInfo, numChannels int, dither bool, callback func(in []int16)) (*portaudio.Stream, string, error) {
+	params := inputStreamParams(dev, numChannels)
+	if portaudio.IsFormatSupported(params, callback) == nil {
+		stream, err := portaudio.OpenStream(params, callback)
+		return stream, sampleFormatInt16, err
+	}
+
+	floatCallback := func(in []float32) {}
+	if portaudio.IsFormatSupported(params, floatCallback) != nil {
+		// Neither works; let OpenStream report why
+		stream, err := portaudio.OpenStream(params, callback)
+		return stream, sampleFormatInt16, err
+	}
+
+	var q quantizer
+	if dither {
+		q = newDitherer().quantize
+	}
+	var buf []int16
+	stream, err := portaudio.OpenStream(params, func(in []float32) {
+		if cap(buf) < len(in) {
+			buf = make([]int16, len(in))
+		}
+		buf = buf[:len(in)]
+		float32ToInt16(in, buf, q)
+		callback(buf)
+	})
+	return stream, sampleFormatFloat32, err
+}
+
+// float32ToInt16 converts samples in [-1, 1] to int16 in out, clipping
+// anything outside that range. Values are rounded, or quantized with q if
+// given. NaN, which some drivers deliver on glitches, becomes silence.
+func float32ToInt16(in []float32, out []int16, q quantizer) {
+	if q == nil {
+		q = func(v float64) int16 { return int16(math.Round(v)) }
+	}
+	for i, v := range in {
+		if math.IsNaN(float64(v)) {
+			out[i] = 0
+			continue
+		}
+		out[i] = q(math.Max(-1, math.Min(1, float64(v))) * math.MaxInt16)
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat32ToInt16(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+	in := []float32{0, 1, -1, 0.5, -0.5, 1.5, -2, inf, -inf, nan}
+	want := []int16{0, 32767, -32767, 16384, -16384, 32767, -32767, 32767, -32767, 0}
+
+	out := make([]int16, len(in))
+	float32ToInt16(in, out, nil)
+	for i := range in {
+		if out[i] != want[i] {
+			t.Errorf("float32ToInt16(%v) = %d, want %d", in[i], out[i], want[i])
+		}
+	}
+}
+
+func TestFloat32ToInt16Dithered(t *testing.T) {
+	// A quiet ramp through fractional LSB values, where plain rounding
+	// distorts the most
+	in := make([]float32, 10000)
+	for i := range in {
+		in[i] = float32(i%100) / 25 / math.MaxInt16
+	}
+	plain := make([]int16, len(in))
+	float32ToInt16(in, plain, nil)
+	dithered := make([]int16, len(in))
+	float32ToInt16(in, dithered, newDitherer().quantize)
+
+	differ := 0
+	for i := range in {
+		d := int(dithered[i]) - int(plain[i])
+		if d < -1 || d > 1 {
+			t.Fatalf("sample %d: dithered %d, rounded %d; want within 1 LSB", i, dithered[i], plain[i])
+		}
+		if d != 0 {
+			differ++
+		}
+	}
+	if differ == 0 {
+		t.Error("dithered output is identical to plain rounding")
+	}
+
+	// Clipping and NaN handling are unchanged
+	edges := []float32{1.5, -2, float32(math.NaN())}
+	out := make([]int16, len(edges))
+	float32ToInt16(edges, out, newDitherer().quantize)
+	if out[0] < math.MaxInt16-1 || out[1] > -math.MaxInt16+1 || out[2] != 0 {
+		t.Errorf("float32ToInt16(%v) with dither = %v", edges, out)
+	}
+}
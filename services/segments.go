@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// SetSegmentOnResume saves each stretch between pauses as its own file, to
+// keep very long meetings manageable: PauseRecording finishes the current
+// segment and ResumeRecording starts the next. StopRecording saves the last
+// one and lists them all, in order, as the recording's SegmentPaths; its
+// WavPath is left empty and Duration covers every segment. Each segment is
+// trimmed like a recording, and no native-rate copies are kept. It's off by
+// default and takes effect at the next pause.
+func (a *AudioService) SetSegmentOnResume(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.segmentOnResume = enabled
+}
+
+// pendingSegment is a stretch of the recording taken by takeSegment, to be
+// written by saveSegment after a.mu is released.
+type pendingSegment struct {
+	snap   audioSnapshot
+	base   string // path without extension
+	frames int
+}
+
+// takeSegment removes the samples captured since the last segment from the
+// recording, returning nil when there are none. Segments are named after
+// the recording's start time and numbered, so they sort in order. Callers
+// must hold a.segmentMu and a.mu.
+func (a *AudioService) takeSegment() *pendingSegment {
+	if len(a.samples) == 0 {
+		return nil
+	}
+	filename := fmt.Sprintf("meeting_%s_part%03d", a.startTime.Format("20060102_150405"), len(a.segments)+1)
+	seg := &pendingSegment{
+		snap:   a.snapshotAudio(),
+		base:   filepath.Join(os.TempDir(), filename),
+		frames: len(a.samples) / max(a.numChannels, 1),
+	}
+	a.samples, a.systemSamples = nil, nil
+	a.segmentFrames += seg.frames
+	return seg
+}
+
+// saveSegment writes seg and adds it to the recording's SegmentPaths. If
+// that fails its samples are put back, so they're saved with the next
+// segment. Callers must hold a.segmentMu, which keeps segments listed in
+// order, but not a.mu.
+func (a *AudioService) saveSegment(seg *pendingSegment) error {
+	path, _, err := writeTempAudio(seg.snap, seg.base, true)
+	a.mu.Lock()
+	if err != nil {
+		a.samples = append(seg.snap.samples, a.samples...)
+		if seg.snap.system != nil {
+			a.systemSamples = append(seg.snap.system, a.systemSamples...)
+		}
+		a.segmentFrames -= seg.frames
+		a.mu.Unlock()
+		return fmt.Errorf("failed to save segment: %w", err)
+	}
+	a.segments = append(a.segments, path)
+	a.mu.Unlock()
+
+	recordings.update(seg.snap.recordingID, func(r *RecordingInfo) {
+		r.SegmentPaths = append(r.SegmentPaths, path)
+	})
+	return nil
+}
+
+// savePausedSegment saves the segment ended by PauseRecording. A failure is
+// only reported, since the samples are kept for the next segment.
+func (a *AudioService) savePausedSegment(seg *pendingSegment) {
+	if err := a.saveSegment(seg); err != nil {
+		msg := fmt.Sprintf("%v; it will be saved with the next one", err)
+		log.Print(msg)
+		application.Get().Event.Emit("audio:warning", AudioWarning{RecordingID: seg.snap.recordingID, Message: msg})
+	}
+}
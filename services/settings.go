@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Settings holds user preferences persisted across launches.
+type Settings struct {
+	Replacements       map[string]string  `json:"replacements,omitempty"`
+	ReplacementOptions ReplacementOptions `json:"replacementOptions"`
+	LanguageModels     map[string]string  `json:"languageModels,omitempty"`
+	ModelCatalogPath   string             `json:"modelCatalogPath,omitempty"`
+	// RecentInputDevices are device names, newest first; see RecentInputDevices
+	RecentInputDevices []string `json:"recentInputDevices,omitempty"`
+	// LastRecording is the most recent recording, for GetLastSession
+	LastRecording *RecordingInfo `json:"lastRecording,omitempty"`
+}
+
+// settingsMu serializes read-modify-write cycles on the settings file,
+// which is shared by all services.
+var settingsMu sync.Mutex
+
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "meeting-transcriber", "settings.json"), nil
+}
+
+// loadSettings reads the settings file. A missing file yields zero settings.
+func loadSettings() (Settings, error) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	return readSettings()
+}
+
+// updateSettings applies fn to the stored settings and writes them back.
+func updateSettings(fn func(*Settings)) error {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	s, err := readSettings()
+	if err != nil {
+		return err
+	}
+	fn(&s)
+	return writeSettings(s)
+}
+
+func readSettings() (Settings, error) {
+	var s Settings
+
+	path, err := settingsPath()
+	if err != nil {
+		return s, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Set the file aside rather than failing every later update, so one
+		// corrupt file doesn't stop settings from being saved for good
+		if renameErr := os.Rename(path, path+".bad"); renameErr != nil {
+			return Settings{}, fmt.Errorf("failed to parse settings: %w", err)
+		}
+		log.Printf("settings were unreadable (%v); moved them to %s and starting over", err, filepath.Base(path)+".bad")
+		return Settings{}, nil
+	}
+	return s, nil
+}
+
+func writeSettings(s Settings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	// Write to a temp file first so a crash can't leave truncated settings
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUpdateSettingsRecoversFromCorruptFile(t *testing.T) {
+	useTempHome(t)
+	path, err := settingsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := []byte(`{"replacements": {"Gym": `)
+	if err := os.WriteFile(path, corrupt, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := updateSettings(func(s *Settings) { s.RecentInputDevices = []string{"USB Mic"} }); err != nil {
+		t.Fatalf("updateSettings() = %v, want it to start over from empty settings", err)
+	}
+	s, err := loadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"USB Mic"}; !reflect.DeepEqual(s.RecentInputDevices, want) {
+		t.Errorf("RecentInputDevices = %v, want %v", s.RecentInputDevices, want)
+	}
+	if data, err := os.ReadFile(path + ".bad"); err != nil || string(data) != string(corrupt) {
+		t.Errorf("corrupt settings not kept as settings.json.bad: %q, %v", data, err)
+	}
+}
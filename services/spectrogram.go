@@ -0,0 +1,145 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/cmplx"
+	"os"
+)
+
+const (
+	spectrogramFFTSize  = 1024
+	spectrogramFloorDb  = -90.0 // dBFS rendered as black
+	maxSpectrogramPixel = 8192  // per dimension
+)
+
+// WriteSpectrogram renders the last recording as a PNG spectrogram with time
+// on the X axis and frequency (0Hz at the bottom up to Nyquist) on the Y axis,
+// for spotting noise, clipping or dropouts. Only available after recording.
+func (a *AudioService) WriteSpectrogram(path string, width, height int) error {
+	if width < 1 || height < 1 || width > maxSpectrogramPixel || height > maxSpectrogramPixel {
+		return fmt.Errorf("spectrogram size must be between 1 and %d pixels, got %dx%d", maxSpectrogramPixel, width, height)
+	}
+
+	a.mu.Lock()
+	if a.state != stateIdle {
+		a.mu.Unlock()
+		return fmt.Errorf("cannot render spectrogram while %s", a.state)
+	}
+	samples := mixToMono(a.samples, a.numChannels)
+	a.mu.Unlock()
+
+	if len(samples) == 0 {
+		return fmt.Errorf("no recording to analyze")
+	}
+
+	img := renderSpectrogram(samples, width, height)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create spectrogram file: %w", err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("failed to write spectrogram: %w", err)
+	}
+	return f.Close()
+}
+
+// renderSpectrogram computes one Hann-windowed FFT frame per image column.
+func renderSpectrogram(samples []int16, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bins := spectrogramFFTSize / 2
+
+	window := make([]float64, spectrogramFFTSize)
+	windowSum := 0.0
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(spectrogramFFTSize-1))
+		windowSum += window[i]
+	}
+
+	frame := make([]complex128, spectrogramFFTSize)
+	db := make([]float64, bins)
+	for x := 0; x < width; x++ {
+		center := int(float64(x) * float64(len(samples)) / float64(width))
+		for i := range frame {
+			j := center - spectrogramFFTSize/2 + i
+			v := 0.0
+			if j >= 0 && j < len(samples) {
+				v = float64(samples[j]) / int16FullScale
+			}
+			frame[i] = complex(v*window[i], 0)
+		}
+		fft(frame)
+
+		for k := range db {
+			// Scale so a full-scale sine reads close to 0 dBFS
+			mag := 2 * cmplx.Abs(frame[k]) / windowSum
+			db[k] = 20 * math.Log10(mag+1e-12)
+		}
+
+		for y := 0; y < height; y++ {
+			// Row 0 is the top of the image, i.e. the highest frequency
+			lo := (height - 1 - y) * bins / height
+			hi := max((height-y)*bins/height, lo+1)
+			peak := spectrogramFloorDb
+			for k := lo; k < hi; k++ {
+				peak = max(peak, db[k])
+			}
+			img.Set(x, y, heatColor((peak-spectrogramFloorDb)/-spectrogramFloorDb))
+		}
+	}
+	return img
+}
+
+// heatColor maps v in [0,1] through black, blue, red, yellow and white.
+func heatColor(v float64) color.RGBA {
+	v = math.Max(0, math.Min(1, v))
+	stops := []color.RGBA{
+		{0, 0, 0, 255},
+		{32, 0, 128, 255},
+		{200, 0, 64, 255},
+		{255, 200, 0, 255},
+		{255, 255, 255, 255},
+	}
+	pos := v * float64(len(stops)-1)
+	i := min(int(pos), len(stops)-2)
+	frac := pos - float64(i)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*frac)
+	}
+	a, b := stops[i], stops[i+1]
+	return color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), 255}
+}
+
+// fft computes an in-place radix-2 FFT. len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := x[start+k]
+				v := x[start+k+size/2] * w
+				x[start+k] = u + v
+				x[start+k+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// speedProbeBytes is how much of a model ProbeDownloadSpeed fetches
+	speedProbeBytes = 2 * 1024 * 1024
+	// speedProbeTimeout bounds the whole probe
+	speedProbeTimeout = 15 * time.Second
+)
+
+// ProbeDownloadSpeed fetches the first couple of megabytes of a model and
+// returns the measured throughput in bytes per second, so the UI can
+// estimate the full download time from the model's size. Nothing is written
+// to disk. CancelDownload stops it.
+func (m *ModelService) ProbeDownloadSpeed(name string) (int64, error) {
+	model, ok := findModelDefinition(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown model: %s", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), speedProbeTimeout)
+	defer cancel()
+	m.mu.Lock()
+	if m.probeCancels == nil {
+		m.probeCancels = make(map[string]context.CancelFunc)
+	}
+	m.probeCancels[name] = cancel
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.probeCancels, name)
+		m.mu.Unlock()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", model.URL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", speedProbeBytes-1))
+
+	connect, idle := m.downloadTimeouts()
+	resp, err := downloadClient(connect, idle).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("speed probe failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	// Time only the transfer, as connection setup is paid once per download
+	start := time.Now()
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, speedProbeBytes))
+	elapsed := time.Since(start)
+	if err != nil && !(errors.Is(err, context.DeadlineExceeded) && n > 0) {
+		if errors.Is(err, context.Canceled) {
+			return 0, fmt.Errorf("speed probe cancelled")
+		}
+		return 0, fmt.Errorf("speed probe failed: %w", err)
+	}
+	if n == 0 || elapsed <= 0 {
+		return 0, fmt.Errorf("speed probe received no data")
+	}
+	return int64(float64(n) / elapsed.Seconds()), nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SplitConfig splits long transcripts into several files at segment
+// boundaries. Zero fields disable that limit; the zero value writes a
+// single file.
+type SplitConfig struct {
+	EveryMinutes int `json:"everyMinutes"` // start a new part every N minutes of audio
+	MaxChars     int `json:"maxChars"`     // start a new part before exceeding N characters
+}
+
+// partSuffix matches the "_part2" style suffix of split transcripts.
+var partSuffix = regexp.MustCompile(`_part\d+$`)
+
+// SetOutputSplit makes TranscribeToFile write long meetings as
+// "<name>_part1.md", "<name>_part2.md", ... Splitting needs segment
+// timings, so without whisper's JSON output a single file is written.
+func (t *TranscribeService) SetOutputSplit(cfg SplitConfig) error {
+	if cfg.EveryMinutes < 0 || cfg.MaxChars < 0 {
+		return fmt.Errorf("split limits must not be negative")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.split = cfg
+	return nil
+}
+
+// splitSegments groups segments into parts. A part ends before the first
+// segment that starts at or after the next EveryMinutes boundary, or that
+// would push it past MaxChars. A single segment longer than MaxChars gets a
+// part of its own rather than being cut.
+func splitSegments(segments []Segment, cfg SplitConfig) [][]Segment {
+	if len(segments) == 0 || (cfg.EveryMinutes <= 0 && cfg.MaxChars <= 0) {
+		return [][]Segment{segments}
+	}
+
+	every := float64(cfg.EveryMinutes * 60)
+	var parts [][]Segment
+	var current []Segment
+	chars := 0
+	boundary := every
+	for _, s := range segments {
+		n := len([]rune(s.Text))
+		if len(current) > 0 {
+			pastTime := cfg.EveryMinutes > 0 && s.Start >= boundary
+			pastSize := cfg.MaxChars > 0 && chars+1+n > cfg.MaxChars
+			if pastTime || pastSize {
+				parts = append(parts, current)
+				current, chars = nil, 0
+			}
+		}
+		if cfg.EveryMinutes > 0 {
+			for s.Start >= boundary {
+				boundary += every
+			}
+		}
+		if len(current) > 0 {
+			chars++ // joining space
+		}
+		current = append(current, s)
+		chars += n
+	}
+	return append(parts, current)
+}
+
+// joinSegmentText returns the plain text of segments.
+func joinSegmentText(segments []Segment) string {
+	texts := make([]string, len(segments))
+	for i, s := range segments {
+		texts[i] = s.Text
+	}
+	return strings.Join(texts, " ")
+}
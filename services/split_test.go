@@ -0,0 +1,67 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSegments(t *testing.T) {
+	seg := func(start float64, text string) Segment {
+		return Segment{Start: start, End: start + 1, Text: text}
+	}
+	tests := []struct {
+		name     string
+		segments []Segment
+		cfg      SplitConfig
+		want     [][]Segment
+	}{
+		{
+			name:     "no limits",
+			segments: []Segment{seg(0, "a"), seg(100, "b")},
+			want:     [][]Segment{{seg(0, "a"), seg(100, "b")}},
+		},
+		{
+			name: "no segments",
+			cfg:  SplitConfig{EveryMinutes: 1},
+			want: [][]Segment{nil},
+		},
+		{
+			name:     "starts exactly on the boundary",
+			segments: []Segment{seg(0, "a"), seg(59.9, "b"), seg(60, "c")},
+			cfg:      SplitConfig{EveryMinutes: 1},
+			want:     [][]Segment{{seg(0, "a"), seg(59.9, "b")}, {seg(60, "c")}},
+		},
+		{
+			name:     "gap over several boundaries",
+			segments: []Segment{seg(0, "a"), seg(200, "b"), seg(230, "c"), seg(240, "d")},
+			cfg:      SplitConfig{EveryMinutes: 1},
+			want:     [][]Segment{{seg(0, "a")}, {seg(200, "b"), seg(230, "c")}, {seg(240, "d")}},
+		},
+		{
+			name: "max chars counts joining spaces",
+			// "aaaa bbbb" is 9 characters, "aaaa bbbb cccc" 14
+			segments: []Segment{seg(0, "aaaa"), seg(1, "bbbb"), seg(2, "cccc")},
+			cfg:      SplitConfig{MaxChars: 9},
+			want:     [][]Segment{{seg(0, "aaaa"), seg(1, "bbbb")}, {seg(2, "cccc")}},
+		},
+		{
+			name:     "max chars counts runes",
+			segments: []Segment{seg(0, "会議です"), seg(1, "はい")},
+			cfg:      SplitConfig{MaxChars: 7},
+			want:     [][]Segment{{seg(0, "会議です"), seg(1, "はい")}},
+		},
+		{
+			name:     "oversized segment gets its own part",
+			segments: []Segment{seg(0, "ab"), seg(1, "much too long"), seg(2, "cd")},
+			cfg:      SplitConfig{MaxChars: 5},
+			want:     [][]Segment{{seg(0, "ab")}, {seg(1, "much too long")}, {seg(2, "cd")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSegments(tt.segments, tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSegments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// AppStatusInfo is a one-shot snapshot of the whole app's readiness.
+type AppStatusInfo struct {
+	AudioReady       bool   `json:"audioReady"`
+	RecordingState   string `json:"recordingState"`
+	ModelInstalled   bool   `json:"modelInstalled"`
+	ModelPath        string `json:"modelPath"`
+	WhisperAvailable bool   `json:"whisperAvailable"`
+	Downloading      bool   `json:"downloading"`
+}
+
+// StatusService aggregates read-only state from the other services so the
+// frontend can fetch a coherent status in a single call.
+type StatusService struct {
+	audio      *AudioService
+	transcribe *TranscribeService
+	model      *ModelService
+}
+
+func NewStatusService(audio *AudioService, transcribe *TranscribeService, model *ModelService) *StatusService {
+	return &StatusService{audio: audio, transcribe: transcribe, model: model}
+}
+
+func (s *StatusService) ServiceName() string {
+	return "StatusService"
+}
+
+func (s *StatusService) ServiceStartup(_ context.Context, _ application.ServiceOptions) error {
+	return nil
+}
+
+func (s *StatusService) ServiceShutdown() error {
+	return nil
+}
+
+func (s *StatusService) AppStatus() AppStatusInfo {
+	status := AppStatusInfo{
+		RecordingState:   s.audio.GetRecordingState(),
+		ModelPath:        s.transcribe.GetModelPath(),
+		WhisperAvailable: s.transcribe.IsWhisperAvailable(),
+		Downloading:      s.model.AnyDownloading(),
+	}
+
+	if dev, err := s.audio.GetSelectedInputDevice(); err == nil {
+		status.AudioReady = dev.Present
+	}
+
+	status.ModelInstalled = status.ModelPath != ""
+	if !status.ModelInstalled {
+		for _, m := range s.model.ListModels() {
+			if m.Exists {
+				status.ModelInstalled = true
+				break
+			}
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// repetitionNGram is the phrase length compared when looking for loops
+	repetitionNGram = 4
+	// repetitionMinTokens avoids flagging short transcripts
+	repetitionMinTokens = 20
+	// repetitionThreshold is the fraction of repeated n-grams that flags a loop
+	repetitionThreshold = 0.5
+)
+
+// SuspiciousTranscript is emitted as "transcribe:suspicious" when whisper's
+// output is empty or looks like a hallucination loop.
+type SuspiciousTranscript struct {
+	RecordingID string `json:"recordingId,omitempty"`
+	WavPath     string `json:"wavPath"`
+	Reason      string `json:"reason"`
+}
+
+// detectSuspicious returns a reason when text is empty or dominated by
+// repeated phrases, which whisper tends to produce over silence.
+func detectSuspicious(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return "empty transcript"
+	}
+	if ratio := repetitionRatio(text); ratio >= repetitionThreshold {
+		return fmt.Sprintf("repetitive output (%.0f%% repeated phrases)", ratio*100)
+	}
+	return ""
+}
+
+// repetitionRatio returns the fraction of n-grams that repeat an earlier one.
+func repetitionRatio(text string) float64 {
+	tokens := tokenize(text)
+	if len(tokens) < repetitionMinTokens {
+		return 0
+	}
+
+	seen := make(map[string]bool)
+	total, repeated := 0, 0
+	for i := 0; i+repetitionNGram <= len(tokens); i++ {
+		key := strings.Join(tokens[i:i+repetitionNGram], "\x00")
+		if seen[key] {
+			repeated++
+		}
+		seen[key] = true
+		total++
+	}
+	return float64(repeated) / float64(total)
+}
+
+// tokenize splits text into lowercase words, treating each CJK character as
+// its own token since those scripts don't separate words with spaces.
+func tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+const (
+	// maxSystemBacklog bounds, in seconds, the system audio waiting to be
+	// mixed, in case its device's clock runs ahead of the microphone's
+	maxSystemBacklog = 0.5
+
+	// Auto-balance; see SetAutoBalance
+	balanceWindow = 10.0 // seconds both levels are measured over
+	balanceBlock  = 0.5  // seconds per level measurement
+	maxBalanceDb  = 12.0
+)
+
+// SelectSystemAudioDevice records a second input device alongside the
+// microphone and mixes the two, to capture the other side of a call. It's
+// usually a loopback device such as BlackHole on macOS or a "Monitor of"
+// source with PulseAudio, since system output isn't an input by default.
+// index is from ListInputDevices; -1, the default, records the microphone
+// alone. Takes effect at the next StartRecording. The mix goes into the
+// transcription audio and live transcription; the native-rate copy and
+// ExportAudio keep the microphone alone.
+func (a *AudioService) SelectSystemAudioDevice(index int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if index == -1 {
+		a.systemDevice = nil
+		return nil
+	}
+	devs, err := portaudio.Devices()
+	if err != nil {
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+	if index < 0 || index >= len(devs) {
+		return fmt.Errorf("invalid device index: %d", index)
+	}
+	dev := devs[index]
+	if dev.MaxInputChannels < 1 {
+		return fmt.Errorf("device %q has no input channels", dev.Name)
+	}
+	if a.selectedDevice != nil && a.selectedDevice.Name == dev.Name {
+		return fmt.Errorf("device %q is already the microphone", dev.Name)
+	}
+	info := newInputDeviceInfo(dev)
+	a.systemDevice = &info
+	return nil
+}
+
+// SetSystemGain adjusts the system audio level by db decibels (0 by
+// default) before it's mixed with the microphone; see SetMicGain.
+func (a *AudioService) SetSystemGain(db float64) error {
+	if math.IsNaN(db) || db < -maxSourceGainDb || db > maxSourceGainDb {
+		return fmt.Errorf("system gain must be between -%.0f and %.0f dB", maxSourceGainDb, maxSourceGainDb)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.systemGainDb = db
+	return nil
+}
+
+// SetAutoBalance evens out the microphone and system audio when they're
+// mixed, on top of their gains: over a rolling window of about ten seconds,
+// the louder source is turned down and the quieter up until their speech
+// levels match, by at most 12 dB. Silence doesn't count, so a source that
+// isn't talking isn't boosted. It's off by default, and the level meter
+// shows the sources without it.
+func (a *AudioService) SetAutoBalance(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.autoBalance = enabled
+}
+
+// openSystemSource opens and starts the system audio device's stream, so
+// it's buffering by the time the microphone's starts. Callers must hold
+// a.mu, with a.nativeSR set for the recording.
+func (a *AudioService) openSystemSource() (*systemSource, error) {
+	dev, err := findInputDevice(*a.systemDevice)
+	if err != nil {
+		return nil, err
+	}
+	if dev == nil {
+		return nil, fmt.Errorf("system audio device %q is no longer available", a.systemDevice.Name)
+	}
+	numChannels := recordingChannels(dev, 2)
+	src := newSystemSource(dev.DefaultSampleRate, a.nativeSR)
+	stream, _, err := openInputStream(dev, numChannels, !a.noDither, func(in []int16) {
+		src.push(in, numChannels)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open system audio stream: %w", err)
+	}
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("failed to start system audio stream: %w", err)
+	}
+	src.stream = stream
+	return src, nil
+}
+
+// systemSource buffers input from the system audio device until the
+// microphone callback mixes it in, as mono at the microphone's rate. The
+// microphone callback takes one sample per frame it records, which keeps
+// the two aligned. It has its own lock so the two device callbacks don't
+// contend for a.mu.
+type systemSource struct {
+	stream audioStream
+
+	mu         sync.Mutex
+	step       float64 // system samples per microphone sample
+	in         []int16 // mono input not resampled yet
+	phase      float64 // position in in of the next resampled sample
+	pending    []int16 // resampled, waiting to be mixed
+	maxPending int
+}
+
+func newSystemSource(fromSR, toSR float64) *systemSource {
+	return &systemSource{
+		step:       fromSR / toSR,
+		maxPending: int(maxSystemBacklog * toSR),
+	}
+}
+
+// push adds a callback buffer from the system audio device.
+func (s *systemSource) push(in []int16, numChannels int) {
+	mono := mixToMono(in, numChannels)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.step == 1 {
+		s.pending = append(s.pending, mono...)
+	} else {
+		// Linear interpolation, carried over from one buffer to the next
+		s.in = append(s.in, mono...)
+		for ; s.phase+1 < float64(len(s.in)); s.phase += s.step {
+			i := int(s.phase)
+			f := s.phase - float64(i)
+			s.pending = append(s.pending, int16(math.Round(float64(s.in[i])*(1-f)+float64(s.in[i+1])*f)))
+		}
+		used := int(s.phase)
+		s.in = append(s.in[:0], s.in[used:]...)
+		s.phase -= float64(used)
+	}
+	if extra := len(s.pending) - s.maxPending; extra > 0 {
+		s.pending = append(s.pending[:0], s.pending[extra:]...)
+	}
+}
+
+// pop returns the next n samples, padded with silence if the system audio
+// device has fallen behind.
+func (s *systemSource) pop(n int) []int16 {
+	out := make([]int16, n)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := copy(out, s.pending)
+	s.pending = append(s.pending[:0], s.pending[k:]...)
+	return out
+}
+
+// mixSystemAudio mixes mono microphone samples with the system audio
+// recorded alongside them at the same rate, sr, applying each source's gain
+// and, if balance is set, the SetAutoBalance correction. The sum is clamped
+// rather than wrapped when it would clip. Returns a new slice.
+func mixSystemAudio(mic, system []int16, sr, micDb, systemDb float64, balance bool) []int16 {
+	micGain, systemGain := math.Pow(10, micDb/20), math.Pow(10, systemDb/20)
+	var correction []float64
+	if balance {
+		correction = balanceCorrection(mic, system, sr, micGain, systemGain)
+	}
+
+	out := make([]int16, len(mic))
+	for i, m := range mic {
+		var s float64
+		if i < len(system) {
+			s = float64(system[i]) * systemGain
+		}
+		v := float64(m) * micGain
+		if correction != nil {
+			// Half the difference each way, in dB
+			c := math.Pow(10, correction[i]/40)
+			v, s = v/c, s*c
+		}
+		out[i] = clampInt16(v + s)
+	}
+	return out
+}
+
+// balanceCorrection returns, per sample, how many dB the system audio is
+// quieter than the microphone over the balanceWindow around it, counting
+// only blocks where a source is above the speech threshold. It's
+// interpolated between blocks so the gain doesn't step, and held where
+// either source has been silent throughout.
+func balanceCorrection(mic, system []int16, sr, micGain, systemGain float64) []float64 {
+	blockLen := max(int(balanceBlock*sr), 1)
+	blocks := (len(mic) + blockLen - 1) / blockLen
+	// Prefix sums of each source's mean square over its active blocks
+	micMS, micN := make([]float64, blocks+1), make([]int, blocks+1)
+	sysMS, sysN := make([]float64, blocks+1), make([]int, blocks+1)
+	for b := range blocks {
+		lo, hi := b*blockLen, min((b+1)*blockLen, len(mic))
+		m := rms(mic[lo:hi]) * micGain
+		var s float64
+		if lo < len(system) {
+			s = rms(system[lo:min(hi, len(system))]) * systemGain
+		}
+		micMS[b+1], micN[b+1] = micMS[b], micN[b]
+		sysMS[b+1], sysN[b+1] = sysMS[b], sysN[b]
+		if m >= speechRMSThreshold {
+			micMS[b+1] += m * m
+			micN[b+1]++
+		}
+		if s >= speechRMSThreshold {
+			sysMS[b+1] += s * s
+			sysN[b+1]++
+		}
+	}
+
+	half := int(balanceWindow / balanceBlock / 2)
+	perBlock := make([]float64, blocks)
+	held := 0.0
+	for b := range blocks {
+		lo, hi := max(b-half, 0), min(b+half+1, blocks)
+		nm, ns := micN[hi]-micN[lo], sysN[hi]-sysN[lo]
+		if nm > 0 && ns > 0 {
+			m := (micMS[hi] - micMS[lo]) / float64(nm)
+			s := (sysMS[hi] - sysMS[lo]) / float64(ns)
+			held = math.Max(-maxBalanceDb, math.Min(maxBalanceDb, 10*math.Log10(m/s)))
+		}
+		perBlock[b] = held
+	}
+
+	out := make([]float64, len(mic))
+	for i := range out {
+		// Block values sit at block centres
+		pos := (float64(i)+0.5)/float64(blockLen) - 0.5
+		b := min(max(int(math.Floor(pos)), 0), blocks-1)
+		next := min(b+1, blocks-1)
+		f := math.Max(0, math.Min(1, pos-float64(b)))
+		out[i] = perBlock[b]*(1-f) + perBlock[next]*f
+	}
+	return out
+}
+
+// trackSourceLevels updates the per-source meters from a microphone buffer
+// and the system audio that goes with it, returning their live mix for the
+// other meters and speech detection. Callers must hold a.mu.
+func (a *AudioService) trackSourceLevels(in []int16, numChannels int, system []int16) []int16 {
+	mic := mixToMono(in, numChannels)
+	a.levelMicRMS = rms(mic) * math.Pow(10, a.micGainDb/20) / int16FullScale
+	a.levelSystemRMS = rms(system) * math.Pow(10, a.systemGainDb/20) / int16FullScale
+	return mixSystemAudio(mic, system, a.nativeSR, a.micGainDb, a.systemGainDb, false)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+// tone returns n samples of a sine at freq and amplitude amp.
+func tone(n int, sr, freq, amp float64) []int16 {
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(amp * math.Sin(2*math.Pi*freq*float64(i)/sr))
+	}
+	return out
+}
+
+func TestSystemSourceResamples(t *testing.T) {
+	src := newSystemSource(44100, 48000)
+	stereo := make([]int16, 2*441)
+	for i := range 441 {
+		stereo[2*i], stereo[2*i+1] = 1000, 3000
+	}
+	for range 100 {
+		src.push(stereo, 2)
+	}
+	// 44100 samples in should come out as about 48000, capped to the backlog
+	if got, want := len(src.pending), src.maxPending; got != want {
+		t.Errorf("pending = %d samples, want the backlog cap %d", got, want)
+	}
+	for i, v := range src.pop(100) {
+		if v != 2000 {
+			t.Fatalf("sample %d = %d, want the channels averaged to 2000", i, v)
+		}
+	}
+
+	src = newSystemSource(44100, 48000)
+	src.push(make([]int16, 4410), 1)
+	if got := len(src.pending); got < 4798 || got > 4800 {
+		t.Errorf("4410 samples at 44.1kHz resampled to %d, want about 4800", got)
+	}
+}
+
+func TestSystemSourcePadsUnderrun(t *testing.T) {
+	src := newSystemSource(48000, 48000)
+	src.push([]int16{1, 2, 3}, 1)
+	got := src.pop(5)
+	want := []int16{1, 2, 3, 0, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pop(5) = %v, want %v", got, want)
+		}
+	}
+	if len(src.pending) != 0 {
+		t.Errorf("%d samples left after popping everything", len(src.pending))
+	}
+}
+
+func TestMixSystemAudio(t *testing.T) {
+	mic := []int16{1000, -1000, 20000, -20000, 100}
+	system := []int16{1000, 1000, 20000, -20000}
+	got := mixSystemAudio(mic, system, 48000, 0, -6.0206, false)
+	// System at half level; clipping clamps; missing system audio is silence
+	want := []int16{1500, -500, 30000, -30000, 100}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("mixSystemAudio() = %v, want %v", got, want)
+		}
+	}
+	if got := mixSystemAudio([]int16{30000}, []int16{30000}, 48000, 0, 0, false); got[0] != math.MaxInt16 {
+		t.Errorf("clipping sum = %d, want it clamped to %d", got[0], math.MaxInt16)
+	}
+}
+
+func TestBalanceCorrection(t *testing.T) {
+	const sr = 8000
+	n := 30 * sr
+	mic := tone(n, sr, 440, 8000)
+	system := tone(n, sr, 300, 4000)
+
+	correction := balanceCorrection(mic, system, sr, 1, 1)
+	for _, i := range []int{0, n / 2, n - 1} {
+		if c := correction[i]; math.Abs(c-6.02) > 0.1 {
+			t.Errorf("correction at %d = %.2f dB, want about 6.02", i, c)
+		}
+	}
+
+	// Half the correction each way brings the two to the same level
+	c := math.Pow(10, correction[n/2]/40)
+	if m, s := rms(mic)/c, rms(system)*c; math.Abs(m-s)/m > 0.02 {
+		t.Errorf("balanced levels %.0f and %.0f, want them matched", m, s)
+	}
+
+	// A source that isn't talking isn't boosted
+	silent := make([]int16, n)
+	for _, c := range balanceCorrection(mic, silent, sr, 1, 1) {
+		if c != 0 {
+			t.Fatalf("correction %.2f dB against a silent system source, want 0", c)
+		}
+	}
+}
+
+func TestHandleInputKeepsSystemAudioAligned(t *testing.T) {
+	const sr = 48000
+	sys := newSystemSource(sr, sr)
+	a := &AudioService{state: stateRecording, nativeSR: sr, numChannels: 2, system: sys}
+
+	sys.push(tone(100, sr, 300, 4000), 1)
+	mic := tone(256*2, sr, 440, 8000)
+	a.handleInput(mic, 2)
+	sys.push(tone(1000, sr, 300, 4000), 1)
+	a.handleInput(mic, 2)
+
+	if got, want := len(a.systemSamples), 2*256; got != want {
+		t.Fatalf("%d system samples for %d frames", got, want)
+	}
+	if got, want := len(a.samples), 2*256*2; got != want {
+		t.Errorf("%d microphone samples, want %d", got, want)
+	}
+	for i := 100; i < 256; i++ {
+		if a.systemSamples[i] != 0 {
+			t.Fatalf("system sample %d = %d, want silence where the device fell behind", i, a.systemSamples[i])
+		}
+	}
+	level := a.GetInputLevel()
+	if level.MicRMSDb <= meterFloorDb || level.SystemRMSDb <= meterFloorDb {
+		t.Errorf("GetInputLevel() = %+v, want both sources metered", level)
+	}
+
+	snap := a.snapshotAudio()
+	snap.sr = sr
+	out := snap.transcriptionSamples(false)
+	want := mixSystemAudio(mixToMono(a.samples, 2), a.systemSamples, sr, 0, 0, false)
+	for i := range want {
+		if out[i] != want[i] {
+			t.Fatalf("transcription sample %d = %d, want the mix %d", i, out[i], want[i])
+		}
+	}
+}
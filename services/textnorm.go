@@ -0,0 +1,100 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// Runs of spaces and tabs within a line
+	extraSpacePattern = regexp.MustCompile(`[ \t]{2,}`)
+	// Space before closing punctuation, e.g. "word ,"
+	spaceBeforePunctPattern = regexp.MustCompile(` +([,.!?;:])`)
+	// Repeated punctuation; dots are limited to an ellipsis separately
+	repeatedPunctPattern = regexp.MustCompile(`([,!?;:])[,!?;:]*`)
+	repeatedDotsPattern  = regexp.MustCompile(`\.{4,}`)
+	// Sentence end followed by a lowercase start, e.g. "done. next"
+	sentenceStartPattern = regexp.MustCompile(`[.!?] (\p{Ll})`)
+)
+
+// noPostProcessLanguages are left untouched by SetTextPostProcess; their
+// scripts have no capitalization and different spacing rules.
+var noPostProcessLanguages = map[string]bool{"ja": true, "zh": true, "ko": true, "yue": true}
+
+// sentenceAbbreviations end in a period without ending the sentence.
+var sentenceAbbreviations = map[string]bool{
+	"e.g": true, "i.e": true, "etc": true, "vs": true,
+	"mr": true, "mrs": true, "ms": true, "dr": true, "approx": true,
+}
+
+// SetTextPostProcess enables a light cleanup of Latin-script transcripts:
+// collapsing extra spaces, removing duplicated punctuation and capitalizing
+// the start of sentences. Japanese, Chinese and Korean text, or any text
+// containing CJK characters, is left unchanged.
+func (t *TranscribeService) SetTextPostProcess(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.textPostProcess = enabled
+}
+
+// tidyText applies the SetTextPostProcess cleanup for language.
+func tidyText(text, language string) string {
+	noCase := func(r rune) bool { return isCJK(r) || unicode.Is(unicode.Hangul, r) }
+	if noPostProcessLanguages[language] || strings.IndexFunc(text, noCase) >= 0 {
+		return text
+	}
+
+	text = extraSpacePattern.ReplaceAllString(text, " ")
+	text = spaceBeforePunctPattern.ReplaceAllString(text, "$1")
+	text = repeatedPunctPattern.ReplaceAllString(text, "$1")
+	text = repeatedDotsPattern.ReplaceAllString(text, "...")
+
+	text = capitalizeSentences(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = capitalizeFirst(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// capitalizeSentences upper-cases lowercase letters that start a sentence
+// after ".", "!" or "?", except after an ellipsis or abbreviations like
+// "e.g.".
+func capitalizeSentences(text string) string {
+	out := []byte(text)
+	for _, m := range sentenceStartPattern.FindAllStringSubmatchIndex(text, -1) {
+		punct, letter := m[0], m[2]
+		if text[punct] == '.' {
+			before := text[:punct]
+			if strings.HasSuffix(before, ".") {
+				// An ellipsis usually continues the sentence
+				continue
+			}
+			word := before[strings.LastIndexFunc(before, unicode.IsSpace)+1:]
+			if sentenceAbbreviations[strings.ToLower(word)] {
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[letter:])
+		if upper := string(unicode.ToUpper(r)); len(upper) == size {
+			copy(out[letter:], upper)
+		}
+	}
+	return string(out)
+}
+
+// capitalizeFirst upper-cases the first letter of line if it's lowercase.
+func capitalizeFirst(line string) string {
+	i := strings.IndexFunc(line, func(r rune) bool { return !unicode.IsSpace(r) })
+	if i < 0 {
+		return line
+	}
+	r, size := utf8.DecodeRuneInString(line[i:])
+	if !unicode.IsLower(r) {
+		return line
+	}
+	return line[:i] + string(unicode.ToUpper(r)) + line[i+size:]
+}
@@ -0,0 +1,27 @@
+package services
+
+import "testing"
+
+func TestTidyText(t *testing.T) {
+	tests := []struct {
+		name, text, language, want string
+	}{
+		{"spacing and punctuation", "hello  there ,, how are you ??", "en", "Hello there, how are you?"},
+		{"sentence starts", "done. next one! and again? yes", "en", "Done. Next one! And again? Yes"},
+		{"ellipsis continues", "well.... maybe not", "en", "Well... maybe not"},
+		{"abbreviation", "bring snacks, e.g. chips", "en", "Bring snacks, e.g. chips"},
+		{"each line", "first line\nsecond line", "en", "First line\nSecond line"},
+		{"japanese language untouched", "会議  です ,, はい", "ja", "会議  です ,, はい"},
+		{"korean language untouched", "hello  there", "ko", "hello  there"},
+		{"mixed cjk in english untouched", "we discussed 会議  notes ,, ok", "en", "we discussed 会議  notes ,, ok"},
+		{"hangul in english untouched", "said 안녕  then left", "auto", "said 안녕  then left"},
+		{"auto latin tidied", "ok  then . bye", "auto", "Ok then. Bye"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tidyText(tt.text, tt.language); got != tt.want {
+				t.Errorf("tidyText(%q, %q) = %q, want %q", tt.text, tt.language, got, tt.want)
+			}
+		})
+	}
+}
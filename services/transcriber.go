@@ -2,154 +2,966 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
+// whisperWaitDelay bounds how long Transcribe waits for whisper's output
+// pipes to close after the process is killed.
+const whisperWaitDelay = 2 * time.Second
+
+const (
+	// transcribeTimeoutAuto derives the timeout from EstimateTranscribeTime
+	transcribeTimeoutAuto time.Duration = -1
+	// autoTimeoutFactor is how many times the estimate to wait before giving up
+	autoTimeoutFactor = 5
+	// minAutoTimeout keeps short recordings from timing out during model load
+	minAutoTimeout = 2 * time.Minute
+)
+
+// Name collision modes for SetOnNameCollision
+const (
+	collisionUnique    = "unique"
+	collisionOverwrite = "overwrite"
+)
+
+// ErrTimeout is returned by Transcribe when whisper exceeds the configured
+// timeout. The partial transcript, if any, is returned alongside it.
+var ErrTimeout = errors.New("transcription timed out")
+
+// modelSpeedFactors approximates processing seconds per second of audio
+var modelSpeedFactors = map[string]float64{
+	"tiny":     0.05,
+	"base":     0.1,
+	"small":    0.25,
+	"medium":   0.5,
+	"large-v3": 1.0,
+}
+
 type TranscribeService struct {
-	language   string
-	modelPath  string
-	whisperBin string
+	// ctx is cancelled on shutdown to kill any running whisper process
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Found at startup and fixed afterwards
+	whisperBin     string
+	whisperVariant string // see findWhisperBinary
+
+	// mu guards cfg and the results of the last run below. Workflow, live,
+	// batch and benchmark runs can overlap each other and the setters, so
+	// each run works from a snapshot of cfg taken when it starts
+	mu             sync.Mutex
+	cfg            transcribeConfig
+	lastTranscript string
+	// suggestedLanguage is the first language detected in auto mode
+	suggestedLanguage string
+	detectedLanguages []string // see DetectedLanguages
+
+	whisperLog whisperLog // see LastWhisperOutput
+
+	// Long flags listed in the binary's help, probed on first use
+	helpOnce  sync.Once
+	helpFlags map[string]bool
+	helpText  string
+}
+
+// transcribeConfig holds the settings changed through TranscribeService's
+// setters; see TranscribeService.config.
+type transcribeConfig struct {
+	language     string
+	modelPath    string
+	includeStats bool
+	timeout      time.Duration
+	retries      int  // see SetTranscribeRetries
+	languageTags bool // see SetLanguageTags
+
+	// Optional GBNF grammar; see SetGrammar
+	grammarPath string
+	grammarRule string
+
+	// retranscribeOverwrite replaces the markdown on re-transcription
+	// instead of writing a new version next to it
+	retranscribeOverwrite bool
+	// onCollision decides what TranscribeToFile does when a file with the
+	// same timestamp already exists
+	onCollision string
+
+	// Post-transcription find/replace dictionary and its compiled form
+	replacementDict map[string]string
+	replacementOpts ReplacementOptions
+	replacements    []replacement
+
+	// languageModels maps language codes to model names or paths
+	languageModels map[string]string
+
+	split SplitConfig // see SetOutputSplit
+	// audioLinks prefixes segments with timestamps linking into the audio
+	audioLinks bool
+
+	progressVerbosity string // see SetProgressVerbosity; "" means detailed
+
+	textPostProcess bool        // see SetTextPostProcess
+	minLength       float64     // seconds; see SetMinTranscribeLength
+	chunks          ChunkConfig // see SetChunkConfig; zero means defaults
+
+	// stereoDownmix is the SetStereoDownmix mode; "" means auto
+	stereoDownmix string
+	// noWorkCopy runs whisper next to the input; see SetTranscribeOnCopy
+	noWorkCopy bool
+	// deleteAudio removes audio after transcription; see
+	// SetDeleteWAVAfterTranscription
+	deleteAudio bool
+
+	// Output files; see SetOutputPlain and SetOutputMarkdown
+	plainOutput bool
+	noMarkdown  bool
+
+	// Markdown layout; see SetHeadingLevel and SetCollapsibleTranscript
+	headingLevel int // 0 means 1
+	collapsible  bool
+}
+
+// config returns a snapshot of the current settings. The maps and slices
+// in it are replaced, never modified, by the setters, so sharing them is
+// safe.
+func (t *TranscribeService) config() transcribeConfig {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg
 }
 
 func (t *TranscribeService) ServiceName() string {
 	return "TranscribeService"
 }
 
-func (t *TranscribeService) ServiceStartup(_ context.Context, _ application.ServiceOptions) error {
-	t.language = "ja"
-	t.modelPath = t.findModelPath()
-	t.whisperBin = t.findWhisperBin()
+func (t *TranscribeService) ServiceStartup(ctx context.Context, _ application.ServiceOptions) error {
+	t.ctx, t.cancel = context.WithCancel(ctx)
+	t.whisperBin, t.whisperVariant = findWhisperBinary()
+	t.mu.Lock()
+	t.cfg.language = "ja"
+	t.cfg.timeout = transcribeTimeoutAuto
+	t.cfg.onCollision = collisionUnique
+	t.mu.Unlock()
+	t.loadSettings()
+	t.mu.Lock()
+	t.cfg.modelPath = t.cfg.findModelPath()
+	t.mu.Unlock()
 	return nil
 }
 
+// loadSettings applies persisted preferences. Invalid entries are logged and
+// skipped so a bad settings file can't prevent startup.
+func (t *TranscribeService) loadSettings() {
+	settings, err := loadSettings()
+	if err != nil {
+		log.Printf("failed to load settings: %v", err)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.languageModels = settings.LanguageModels
+
+	reps, err := compileReplacements(settings.Replacements, settings.ReplacementOptions)
+	if err != nil {
+		log.Printf("ignoring saved replacements: %v", err)
+		return
+	}
+	t.cfg.replacementDict = settings.Replacements
+	t.cfg.replacementOpts = settings.ReplacementOptions
+	t.cfg.replacements = reps
+}
+
 func (t *TranscribeService) ServiceShutdown() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
 	return nil
 }
 
+// runContext returns the context whisper processes run under.
+func (t *TranscribeService) runContext() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
 func (t *TranscribeService) Transcribe(wavPath string) (string, error) {
+	result, err := t.transcribe(t.config(), wavPath)
+	return result.Text, err
+}
+
+// transcribe runs whisper on wavPath with the settings in cfg and returns
+// the cleaned-up text along with timed segments when whisper's JSON output
+// is available.
+func (t *TranscribeService) transcribe(cfg transcribeConfig, wavPath string) (transcription, error) {
+	result, err := t.transcribeContext(t.runContext(), cfg, wavPath)
+	if err != nil {
+		return result, err
+	}
+	t.mu.Lock()
+	t.lastTranscript = result.Text
+	t.noteDetectedLanguage(result.Language)
+	t.detectedLanguages = segmentLanguages(result.Segments, result.Language)
+	t.mu.Unlock()
+
+	if reason := detectSuspicious(result.Text); reason != "" {
+		// Still return the text; the UI decides how to warn the user
+		application.Get().Event.Emit("transcribe:suspicious", SuspiciousTranscript{
+			RecordingID: recordings.idForPath(wavPath),
+			WavPath:     wavPath,
+			Reason:      reason,
+		})
+	}
+	return result, nil
+}
+
+// transcribeContext runs whisper on wavPath, killing it when ctx is done.
+// cfg is the settings snapshot the run uses throughout, so changes made
+// while it runs apply to the next one. extra flags are passed to whisper
+// before the input.
+func (t *TranscribeService) transcribeContext(ctx context.Context, cfg transcribeConfig, wavPath string, extra ...string) (transcription, error) {
+	var result transcription
+	sourcePath := wavPath
+
 	if !t.IsWhisperAvailable() {
-		return "", fmt.Errorf("whisper-cpp is not installed. Please install it with: brew install whisper-cpp")
+		return result, fmt.Errorf("whisper-cpp is not installed. Please install it with: brew install whisper-cpp")
 	}
 
-	modelPath := t.modelPath
+	modelPath := cfg.modelPath
 	if modelPath == "" {
-		return "", fmt.Errorf("whisper model not found. Please download a model file")
+		return result, fmt.Errorf("whisper model not found. Please download a model file")
 	}
+	if err := checkModelLanguage(modelPath, cfg.language); err != nil {
+		return result, err
+	}
+	if err := t.checkModelFormat(modelPath); err != nil {
+		return result, err
+	}
+
+	if isCompressedAudio(wavPath) {
+		decoded, err := decodeToWAV(wavPath)
+		if err != nil {
+			return result, err
+		}
+		defer os.Remove(decoded)
+		wavPath = decoded
+	} else if err := validateWAV(wavPath); err != nil {
+		return result, err
+	}
+	mono, err := cfg.downmixInput(wavPath)
+	if err != nil {
+		return result, err
+	}
+	if mono != "" {
+		defer os.Remove(mono)
+		wavPath = mono
+	}
+	if wavPath == sourcePath && !cfg.noWorkCopy {
+		// Decoded and downmixed inputs are already in the temp directory
+		work, cleanup, err := workingCopy(wavPath)
+		if err != nil {
+			return result, err
+		}
+		defer cleanup()
+		wavPath = work
+	}
+
+	grammar, err := t.grammarArgs(cfg)
+	if err != nil {
+		return result, err
+	}
+	writeJSON := t.writesJSON()
+	printProgress := t.supportsFlag("--print-progress")
+	extra = append(grammar, extra...)
+	if printProgress {
+		extra = append(extra, "--print-progress")
+	}
+	args := whisperArgs(t.whisperVariant, modelPath, cfg.language, wavPath, writeJSON, extra...)
 
-	args := []string{
-		"--model", modelPath,
-		"--language", t.language,
-		"--output-txt",
-		"--no-prints",
-		wavPath,
+	timeout := cfg.transcribeTimeout(wavPath)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
+	ctx, untrack := trackTranscription(ctx)
+	defer untrack()
 
-	cmd := exec.Command(t.whisperBin, args...)
-	output, err := cmd.CombinedOutput()
+	outputs := pendingWhisperOutputs(wavPath)
+	output, err := t.runWhisper(ctx, args, cfg.retries, t.progressReporter(sourcePath, wavPath, printProgress))
 	if err != nil {
-		return "", fmt.Errorf("whisper-cpp failed: %w\nOutput: %s", err, string(output))
+		// Don't leave partial output to be picked up by a later run
+		removeWhisperOutputs(outputs)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			// Segments printed before the kill are the best partial result available
+			result.Text = strings.TrimSpace(string(output))
+			return result, fmt.Errorf("%w after %s", ErrTimeout, timeout)
+		}
+		if ctx.Err() != nil {
+			return result, fmt.Errorf("transcription cancelled: %w", ctx.Err())
+		}
+		// The full output is available from LastWhisperOutput
+		return result, fmt.Errorf("whisper-cpp failed: %w\nOutput: %s", err, outputTail(output))
 	}
 
-	// whisper-cpp with --output-txt writes to <input>.txt
-	txtPath := wavPath + ".txt"
+	// --output-txt writes <input>.txt, named either way by whisper version
+	txtPath := whisperOutputPath(wavPath, "txt")
 	text, err := os.ReadFile(txtPath)
 	if err != nil {
 		// Fallback: try to use stdout
-		return strings.TrimSpace(string(output)), nil
+		text = output
+	} else {
+		os.Remove(txtPath)
+	}
+
+	// --output-json writes <input>.json with per-segment timings
+	// (only looked for when requested, so an unrelated <name>.json survives)
+	jsonPath := whisperOutputPath(wavPath, "json")
+	if data, err := os.ReadFile(jsonPath); writeJSON && err == nil {
+		os.Remove(jsonPath)
+		if segments, lang, err := parseWhisperJSON(data); err == nil {
+			for i := range segments {
+				segments[i].Text = cfg.postProcess(segments[i].Text)
+			}
+			result.Segments = segments
+			result.Language = lang
+		}
+	}
+	if result.Language == "" && cfg.language == "auto" {
+		result.Language = detectedLanguage(string(output))
 	}
-	defer os.Remove(txtPath)
+	if cfg.language == "auto" && result.Language != "" {
+		tagSegmentLanguage(result.Segments, result.Language)
+	}
+
+	result.Text = cfg.postProcess(string(text))
+	return result, nil
+}
 
-	return strings.TrimSpace(string(text)), nil
+// normalizeWhisperText strips a UTF-8 byte order mark and converts CRLF and
+// lone CR line endings to LF, which some whisper builds write on Windows.
+// Both are ASCII or whole-rune patterns, so multibyte text is untouched.
+func normalizeWhisperText(text string) string {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
 }
 
+// postProcess cleans up raw whisper output before it's returned or saved.
+func (c transcribeConfig) postProcess(text string) string {
+	text = strings.TrimSpace(normalizeWhisperText(text))
+	if c.textPostProcess {
+		text = tidyText(text, c.language)
+	}
+	return applyReplacements(text, c.replacements)
+}
+
+// TranscribeToFile transcribes wavPath and saves it as markdown. With
+// SetOutputSplit it returns the first part; see TranscribeToFileParts.
 func (t *TranscribeService) TranscribeToFile(wavPath string) (string, error) {
-	text, err := t.Transcribe(wavPath)
+	return t.TranscribeToFileWithMeta(wavPath, MeetingMeta{})
+}
+
+// TranscribeToFileWithMeta is TranscribeToFile with a title, attendees, tags
+// and notes saved as YAML front matter, so transcripts can be filtered later.
+func (t *TranscribeService) TranscribeToFileWithMeta(wavPath string, meta MeetingMeta) (string, error) {
+	paths, err := t.TranscribeToFileParts(wavPath, meta)
 	if err != nil {
 		return "", err
 	}
+	return paths[0], nil
+}
 
-	saveDir := filepath.Join(os.Getenv("HOME"), "Documents", "Transcriptions")
-	if err := os.MkdirAll(saveDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create save directory: %w", err)
+// TranscribeToFileParts is TranscribeToFileWithMeta returning every markdown
+// file written, which is more than one when SetOutputSplit splits it. With
+// SetOutputMarkdown(false) the plain text files are returned instead.
+func (t *TranscribeService) TranscribeToFileParts(wavPath string, meta MeetingMeta) ([]string, error) {
+	res, err := t.TranscribeToFileResult(wavPath, meta)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.MarkdownPaths) == 0 {
+		// SetOutputMarkdown(false)
+		return res.TextPaths, nil
 	}
+	return res.MarkdownPaths, nil
+}
 
-	timestamp := time.Now().Format("2006-01-02_150405")
-	mdPath := filepath.Join(saveDir, timestamp+".md")
+// TranscribeResult lists everything TranscribeToFileResult produced.
+type TranscribeResult struct {
+	MarkdownPaths   []string `json:"markdownPaths"`             // one per part; none with SetOutputMarkdown(false)
+	TextPaths       []string `json:"textPaths,omitempty"`       // see SetOutputPlain
+	TranscriptPaths []string `json:"transcriptPaths,omitempty"` // structured sidecars, when segments were timed
+	// AudioPath is the recording copied next to the transcript, or "" if it
+	// couldn't be copied or was deleted; see SetDeleteWAVAfterTranscription
+	AudioPath string  `json:"audioPath,omitempty"`
+	Duration  float64 `json:"duration"` // seconds; 0 if unknown
+	Model     string  `json:"model"`    // catalog name, or the model's file name
+	ModelPath string  `json:"modelPath"`
+	// Language is the one detected with "auto", otherwise the one set
+	Language string `json:"language"`
+}
 
-	content := fmt.Sprintf("# Meeting Transcription\n\n**Date:** %s\n\n---\n\n%s\n",
-		time.Now().Format("2006-01-02 15:04:05"),
-		text,
-	)
+// TranscribeToFileResult is TranscribeToFileWithMeta returning every file
+// written along with details of the run, for showing a result card.
+func (t *TranscribeService) TranscribeToFileResult(wavPath string, meta MeetingMeta) (TranscribeResult, error) {
+	var res TranscribeResult
+	meta, err := cleanMeetingMeta(meta)
+	if err != nil {
+		return res, err
+	}
+	cfg := t.config()
+	if err := cfg.checkMinLength(wavPath); err != nil {
+		return res, err
+	}
 
-	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
-		return "", fmt.Errorf("failed to write transcription file: %w", err)
+	result, err := t.transcribe(cfg, wavPath)
+	if err != nil {
+		return res, err
 	}
+	return saveTranscription(cfg, wavPath, meta, result)
+}
 
-	// Copy WAV file to the same directory for verification
-	wavDst := filepath.Join(saveDir, timestamp+".wav")
+// saveTranscription writes the markdown, plain text and sidecar files for a
+// finished transcription of wavPath, along with a copy of the audio.
+func saveTranscription(cfg transcribeConfig, wavPath string, meta MeetingMeta, result transcription) (TranscribeResult, error) {
+	var res TranscribeResult
+	saveDir, err := transcriptionsDir()
+	if err != nil {
+		return res, err
+	}
+
+	now := time.Now()
+	timestamp := now.Format("2006-01-02_150405")
+	if cfg.onCollision != collisionOverwrite {
+		var release func()
+		timestamp, release = uniqueBaseName(saveDir, timestamp, ".md", "_part1.md", ".txt", "_part1.txt", ".wav", ".flac", transcriptSuffix)
+		defer release()
+	}
+
+	recordingID := recordings.idForPath(wavPath)
+	audioHash := ""
+	if info, ok := recordings.get(recordingID); ok && info.AudioHash != "" {
+		audioHash = info.AudioHash
+	} else if h, err := audioFileHash(wavPath); err == nil {
+		audioHash = h
+	}
+
+	// Copy the recording (WAV, or FLAC with SetCompressedTemp) to the same
+	// directory for verification. This comes first so timestamp links
+	// never point at a file that doesn't exist yet
+	var savedAudioPath string
+	wavDst := filepath.Join(saveDir, timestamp+strings.ToLower(filepath.Ext(wavPath)))
 	if wavData, err := os.ReadFile(wavPath); err == nil {
-		os.WriteFile(wavDst, wavData, 0644)
+		if os.WriteFile(wavDst, wavData, 0644) == nil {
+			savedAudioPath = wavDst
+		}
 	}
 
-	return mdPath, nil
+	res, err = cfg.writeTranscriptOutputs(transcriptOutput{
+		dir:            saveDir,
+		base:           timestamp,
+		recordingID:    recordingID,
+		audioHash:      audioHash,
+		savedAudioPath: savedAudioPath,
+		date:           now.Format("2006-01-02 15:04:05"),
+	}, meta, result)
+	if err != nil {
+		return res, err
+	}
+	res.AudioPath = savedAudioPath
+	res.Duration = recordingDuration(wavPath)
+	res.Model = modelName(cfg.modelPath)
+	res.ModelPath = cfg.modelPath
+	res.Language = cfg.language
+	if result.Language != "" {
+		res.Language = result.Language
+	}
+	cfg.recordTranscription(recordingID, res, savedAudioPath)
+	if slices.Contains(cfg.deleteTranscribedAudio(recordingID, wavPath, savedAudioPath), savedAudioPath) {
+		res.AudioPath = ""
+	}
+	return res, nil
 }
 
-func (t *TranscribeService) IsWhisperAvailable() bool {
-	return t.whisperBin != ""
+// transcriptOutput says where writeTranscriptOutputs saves a transcription
+// and what its sidecars record.
+type transcriptOutput struct {
+	dir            string
+	base           string // files are base.md, or base_part1.md and so on when split
+	recordingID    string
+	audioHash      string
+	savedAudioPath string // recording that timestamp links point at, or ""
+	date           string
 }
 
-func (t *TranscribeService) findWhisperBin() string {
-	// Try PATH first
-	for _, name := range []string{"whisper-cli", "whisper-cpp"} {
-		if p, err := exec.LookPath(name); err == nil {
-			return p
+// writeTranscriptOutputs writes the markdown, plain text and sidecar files
+// for result, split according to SetOutputSplit, and returns their paths.
+func (c transcribeConfig) writeTranscriptOutputs(out transcriptOutput, meta MeetingMeta, result transcription) (TranscribeResult, error) {
+	var res TranscribeResult
+	speakerLabels := hasMultipleSpeakers(result.Segments)
+	parts := splitSegments(result.Segments, c.split)
+	for i, segments := range parts {
+		base := out.base
+		tf := &TranscriptFile{
+			RecordingID:   out.recordingID,
+			Date:          out.date,
+			Text:          result.Text,
+			Segments:      segments,
+			SpeakerLabels: speakerLabels,
+			AudioHash:     out.audioHash,
+			LanguageTags:  c.languageTags,
 		}
+		part := ""
+		if len(parts) > 1 {
+			base = fmt.Sprintf("%s_part%d", out.base, i+1)
+			tf.Text = joinSegmentText(segments)
+			part = fmt.Sprintf("%d of %d", i+1, len(parts))
+			tf.Part = part
+		}
+		if !meta.isEmpty() {
+			tf.Meta = &meta
+		}
+		if c.audioLinks && out.savedAudioPath != "" {
+			tf.AudioFile = filepath.Base(out.savedAudioPath)
+		}
+
+		if !c.noMarkdown {
+			mdPath := filepath.Join(out.dir, base+".md")
+			if err := c.writeMarkdownPart(mdPath, tf.body(), tf.Date, meta, part); err != nil {
+				return res, err
+			}
+			res.MarkdownPaths = append(res.MarkdownPaths, mdPath)
+		}
+		if c.plainOutput {
+			txtPath := filepath.Join(out.dir, base+".txt")
+			if err := writePlainText(txtPath, tf.Text); err != nil {
+				return res, err
+			}
+			res.TextPaths = append(res.TextPaths, txtPath)
+		}
+
+		// Structured sidecar for speaker relabeling and later re-rendering
+		if len(tf.Segments) > 0 {
+			p := filepath.Join(out.dir, base+transcriptSuffix)
+			if err := writeTranscriptFile(p, tf); err != nil {
+				log.Printf("failed to save transcript sidecar: %v", err)
+			} else {
+				res.TranscriptPaths = append(res.TranscriptPaths, p)
+			}
+		}
+	}
+	return res, nil
+}
+
+// recordTranscription points the recording's registry entry at the files
+// of res.
+func (c transcribeConfig) recordTranscription(recordingID string, res TranscribeResult, savedAudioPath string) {
+	primary := res.MarkdownPaths
+	if c.noMarkdown {
+		primary = res.TextPaths
 	}
+	recordings.update(recordingID, func(r *RecordingInfo) {
+		r.MarkdownPath = primary[0]
+		// Cleared when there's no sidecar, so a re-transcription doesn't
+		// keep pointing at the previous one
+		r.TranscriptPath = ""
+		if len(res.TranscriptPaths) > 0 {
+			r.TranscriptPath = res.TranscriptPaths[0]
+		}
+		r.SavedAudioPath = savedAudioPath
+	})
+}
 
-	// macOS GUI apps don't inherit shell PATH, so check Homebrew paths directly
-	homebrewBins := []string{
-		"/opt/homebrew/bin", // Apple Silicon
-		"/usr/local/bin",   // Intel
+// SetHeadingLevel sets the level (1-6) of the title heading in saved
+// markdown, so transcripts nest correctly when embedded in other documents.
+func (t *TranscribeService) SetHeadingLevel(n int) error {
+	if n < 1 || n > 6 {
+		return fmt.Errorf("heading level must be between 1 and 6, got %d", n)
 	}
-	binNames := []string{"whisper-cli", "whisper-cpp"}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.headingLevel = n
+	return nil
+}
 
-	for _, dir := range homebrewBins {
-		for _, name := range binNames {
-			p := filepath.Join(dir, name)
-			if _, err := os.Stat(p); err == nil {
-				return p
+func (c transcribeConfig) headingLevelOrDefault() int {
+	if c.headingLevel == 0 {
+		return 1
+	}
+	return c.headingLevel
+}
+
+// SetCollapsibleTranscript wraps the transcript text of saved markdown in a
+// <details> block, so long notes start collapsed in viewers that render HTML.
+func (t *TranscribeService) SetCollapsibleTranscript(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.collapsible = enabled
+}
+
+// SetAudioLinkedTimestamps writes each segment of saved transcripts on its
+// own line, prefixed with a timestamp linking to that point in the saved
+// recording, e.g. "[[00:01:23]](2026-01-02_150405.wav#t=83)". Whether the
+// link seeks depends on the markdown viewer supporting media fragments
+// (#t=); elsewhere it just opens the audio file. Needs segment timings.
+func (t *TranscribeService) SetAudioLinkedTimestamps(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.audioLinks = enabled
+}
+
+// SetOnNameCollision chooses what TranscribeToFile does when a transcript
+// from the same second already exists: "unique" (the default) appends -1,
+// -2, ... to the name, "overwrite" replaces the existing files.
+func (t *TranscribeService) SetOnNameCollision(mode string) error {
+	if mode != collisionUnique && mode != collisionOverwrite {
+		return fmt.Errorf("unknown name collision mode %q; use %q or %q", mode, collisionUnique, collisionOverwrite)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.onCollision = mode
+	return nil
+}
+
+// reservedNames holds the base names handed out by uniqueBaseName whose
+// files may not exist yet, keyed by path without suffix.
+var reservedNames = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: make(map[string]bool)}
+
+// uniqueBaseName returns base, or base-N with the smallest N, such that no
+// file named base+suffix exists in dir for any of the suffixes. The name is
+// reserved until release is called, once its files are written, so saves
+// finishing in the same second don't pick the same one.
+func uniqueBaseName(dir, base string, suffixes ...string) (name string, release func()) {
+	reservedNames.Lock()
+	defer reservedNames.Unlock()
+	name = base
+	for n := 1; ; n++ {
+		key := filepath.Join(dir, name)
+		free := !reservedNames.paths[key]
+		for _, suffix := range suffixes {
+			if !free {
+				break
+			}
+			if _, err := os.Stat(key + suffix); err == nil {
+				free = false
 			}
 		}
+		if free {
+			reservedNames.paths[key] = true
+			return name, func() {
+				reservedNames.Lock()
+				delete(reservedNames.paths, key)
+				reservedNames.Unlock()
+			}
+		}
+		name = fmt.Sprintf("%s-%d", base, n)
 	}
+}
 
+// writeMarkdown renders text with the markdown template and writes it to mdPath.
+func (c transcribeConfig) writeMarkdown(mdPath, text, date string, meta MeetingMeta) error {
+	return c.writeMarkdownPart(mdPath, text, date, meta, "")
+}
+
+// writeMarkdownPart is writeMarkdown for one part of a split transcript,
+// with part like "2 of 3".
+func (c transcribeConfig) writeMarkdownPart(mdPath, text, date string, meta MeetingMeta, part string) error {
+	content, err := renderMarkdown(markdownData{
+		TranscriptStats: computeTranscriptStats(text),
+		FrontMatter:     renderFrontMatter(meta, date),
+		Title:           meta.Title,
+		Date:            date,
+		Part:            part,
+		Text:            text,
+		IncludeStats:    c.includeStats,
+		Heading:         strings.Repeat("#", c.headingLevelOrDefault()),
+		Collapsible:     c.collapsible,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render transcription: %w", err)
+	}
+
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write transcription file: %w", err)
+	}
+	return nil
+}
+
+// transcriptionsDir returns the directory transcripts are saved to,
+// creating it if needed.
+func transcriptionsDir() (string, error) {
+	docsDir, err := documentsDir()
+	if err != nil {
+		return "", err
+	}
+	saveDir := filepath.Join(docsDir, "Transcriptions")
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create save directory: %w", err)
+	}
+	return saveDir, nil
+}
+
+// documentsDir returns the user's documents directory. On Linux it honors
+// XDG_DOCUMENTS_DIR and the user-dirs.dirs file, where the folder may be
+// localized or relocated.
+func documentsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return "", fmt.Errorf("cannot determine home directory to save transcriptions")
+	}
+
+	if runtime.GOOS == "linux" {
+		if dir := os.Getenv("XDG_DOCUMENTS_DIR"); filepath.IsAbs(dir) {
+			return dir, nil
+		}
+		if dir := xdgUserDir(home, "XDG_DOCUMENTS_DIR"); dir != "" {
+			return dir, nil
+		}
+	}
+
+	return filepath.Join(home, "Documents"), nil
+}
+
+// xdgUserDir reads a directory entry from $XDG_CONFIG_HOME/user-dirs.dirs,
+// which is written by xdg-user-dirs-update. Returns "" when not found.
+func xdgUserDir(home, key string) string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configDir) {
+		configDir = filepath.Join(home, ".config")
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, "user-dirs.dirs"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || name != key {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if rest, ok := strings.CutPrefix(value, "$HOME"); ok {
+			value = home + rest
+		}
+		// A value of $HOME itself means the directory is disabled
+		if !filepath.IsAbs(value) || filepath.Clean(value) == filepath.Clean(home) {
+			return ""
+		}
+		return value
+	}
 	return ""
 }
 
+// SetTranscribeTimeout limits how long a single whisper run may take. Zero
+// disables the timeout; a negative value restores the default, which is a
+// generous multiple of EstimateTranscribeTime.
+func (t *TranscribeService) SetTranscribeTimeout(d time.Duration) {
+	if d < 0 {
+		d = transcribeTimeoutAuto
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.timeout = d
+}
+
+// EstimateTranscribeTime returns the expected processing time in seconds for
+// wavPath with the current model, based on the recording length.
+func (t *TranscribeService) EstimateTranscribeTime(wavPath string) (float64, error) {
+	return estimateTranscribeTime(wavPath, t.config().modelPath)
+}
+
+func estimateTranscribeTime(wavPath, modelPath string) (float64, error) {
+	audioSeconds, err := wavDuration(wavPath)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read recording: %w", err)
+	}
+	return audioSeconds * modelSpeedFactor(modelPath), nil
+}
+
+func (c transcribeConfig) transcribeTimeout(wavPath string) time.Duration {
+	if c.timeout != transcribeTimeoutAuto {
+		return c.timeout
+	}
+
+	timeout := minAutoTimeout
+	if est, err := estimateTranscribeTime(wavPath, c.modelPath); err == nil {
+		if d := time.Duration(est * autoTimeoutFactor * float64(time.Second)); d > timeout {
+			timeout = d
+		}
+	}
+	return timeout
+}
+
+// modelSpeedFactor guesses the model tier from its filename. Unknown models
+// are assumed to be as slow as the largest one.
+func modelSpeedFactor(modelPath string) float64 {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(modelPath), "ggml-"), ".bin")
+	name = strings.TrimSuffix(name, ".en")
+	if f, ok := modelSpeedFactors[name]; ok {
+		return f
+	}
+	return modelSpeedFactors["large-v3"]
+}
+
+func (t *TranscribeService) IsWhisperAvailable() bool {
+	return t.whisperBin != ""
+}
+
+// GetWhisperVariant returns which whisper binary was found: "whisper-cli",
+// "whisper-cpp", "legacy" for the old main example, or "" if none.
+func (t *TranscribeService) GetWhisperVariant() string {
+	return t.whisperVariant
+}
+
 func (t *TranscribeService) GetModelPath() string {
-	return t.modelPath
+	return t.config().modelPath
 }
 
 func (t *TranscribeService) RefreshModelPath() string {
-	t.modelPath = t.findModelPath()
-	return t.modelPath
+	path := t.config().findModelPath()
+	t.setModelPath(path)
+	return path
+}
+
+// ModelChanged is the payload of "transcribe:model-changed".
+type ModelChanged struct {
+	ModelPath string `json:"modelPath"` // "" when no model was found
+	Model     string `json:"model"`     // catalog name, or "" for unlisted files
+}
+
+// setModelPath switches the active model, emitting
+// "transcribe:model-changed" when it differs from the current one.
+func (t *TranscribeService) setModelPath(path string) {
+	t.mu.Lock()
+	changed := path != t.cfg.modelPath
+	t.cfg.modelPath = path
+	t.mu.Unlock()
+	if !changed {
+		return
+	}
+	var name string
+	if path != "" {
+		name = catalogModelName(path)
+	}
+	application.Get().Event.Emit("transcribe:model-changed", ModelChanged{ModelPath: path, Model: name})
+}
+
+// catalogModelName returns the catalog name of the model file at path, or
+// "" if it isn't listed.
+func catalogModelName(path string) string {
+	for _, m := range modelCatalog() {
+		if m.FileName == filepath.Base(path) {
+			return m.Name
+		}
+	}
+	return ""
+}
+
+// modelName names the model at path for display: its catalog name, or
+// else its file name.
+func modelName(path string) string {
+	if name := catalogModelName(path); name != "" {
+		return name
+	}
+	return filepath.Base(path)
 }
 
 func (t *TranscribeService) SetLanguage(lang string) error {
 	if lang == "" {
 		return fmt.Errorf("language cannot be empty")
 	}
-	t.language = lang
+	t.mu.Lock()
+	t.cfg.language = lang
+	if lang != "auto" {
+		t.suggestedLanguage = ""
+	}
+	cfg := t.cfg
+	t.mu.Unlock()
+
+	if len(cfg.languageModels) > 0 {
+		t.setModelPath(cfg.findModelPath())
+	}
 	return nil
 }
 
-func (t *TranscribeService) findModelPath() string {
+// TranscriptStats returns word count, character count and estimated reading
+// time for text. CJK characters are counted individually.
+func (t *TranscribeService) TranscriptStats(text string) TranscriptStats {
+	return computeTranscriptStats(text)
+}
+
+// SetIncludeStats toggles the word count / reading time line in saved markdown.
+func (t *TranscribeService) SetIncludeStats(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.includeStats = enabled
+}
+
+// SetReplacements sets a find/replace dictionary applied to every transcript,
+// used to fix names whisper consistently gets wrong (e.g. "Gym" -> "Jim").
+// The dictionary is persisted in settings.
+func (t *TranscribeService) SetReplacements(dict map[string]string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reps, err := compileReplacements(dict, t.cfg.replacementOpts)
+	if err != nil {
+		return err
+	}
+	if err := updateSettings(func(s *Settings) { s.Replacements = dict }); err != nil {
+		return fmt.Errorf("failed to save replacements: %w", err)
+	}
+	t.cfg.replacementDict = dict
+	t.cfg.replacements = reps
+	return nil
+}
+
+// SetReplacementOptions changes how replacement keys are matched.
+func (t *TranscribeService) SetReplacementOptions(opts ReplacementOptions) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reps, err := compileReplacements(t.cfg.replacementDict, opts)
+	if err != nil {
+		return err
+	}
+	if err := updateSettings(func(s *Settings) { s.ReplacementOptions = opts }); err != nil {
+		return fmt.Errorf("failed to save replacement options: %w", err)
+	}
+	t.cfg.replacementOpts = opts
+	t.cfg.replacements = reps
+	return nil
+}
+
+func (t *TranscribeService) GetReplacements() map[string]string {
+	return t.config().replacementDict
+}
+
+// homebrewModelDirs are where Homebrew's whisper-cpp installs models.
+var homebrewModelDirs = []string{
+	"/opt/homebrew/share/whisper-cpp/models",
+	"/usr/local/share/whisper-cpp/models",
+}
+
+func (c transcribeConfig) findModelPath() string {
+	if p := c.mappedModelPath(); p != "" {
+		return p
+	}
+
 	// Check common locations for whisper models
 	candidates := []string{
 		"models/ggml-large-v3.bin",
@@ -166,11 +978,6 @@ func (t *TranscribeService) findModelPath() string {
 		}
 	}
 
-	// Check Homebrew whisper-cpp model locations
-	homebrewPaths := []string{
-		"/opt/homebrew/share/whisper-cpp/models",
-		"/usr/local/share/whisper-cpp/models",
-	}
 	modelNames := []string{
 		"ggml-large-v3.bin",
 		"ggml-medium.bin",
@@ -178,7 +985,8 @@ func (t *TranscribeService) findModelPath() string {
 		"ggml-small.bin",
 	}
 
-	for _, dir := range homebrewPaths {
+	// Check Homebrew whisper-cpp model locations
+	for _, dir := range homebrewModelDirs {
 		for _, model := range modelNames {
 			p := filepath.Join(dir, model)
 			if _, err := os.Stat(p); err == nil {
@@ -187,14 +995,11 @@ func (t *TranscribeService) findModelPath() string {
 		}
 	}
 
-	// Check home directory models
-	home := os.Getenv("HOME")
-	if home != "" {
-		for _, model := range modelNames {
-			p := filepath.Join(home, ".local", "share", "whisper-cpp", "models", model)
-			if _, err := os.Stat(p); err == nil {
-				return p
-			}
+	// Check the directory ModelService downloads to
+	for _, model := range modelNames {
+		p := filepath.Join(modelsDir(), model)
+		if _, err := os.Stat(p); err == nil {
+			return p
 		}
 	}
 
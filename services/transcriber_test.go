@@ -0,0 +1,116 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestNormalizeWhisperText(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"bom", "\ufeffhello", "hello"},
+		{"crlf", "one\r\ntwo\r\n", "one\ntwo\n"},
+		{"lone cr", "one\rtwo", "one\ntwo"},
+		{"bom and crlf", "\ufeff会議です\r\nはい\r\n", "会議です\nはい\n"},
+		{"bom only at start", "a\ufeffb", "a\ufeffb"},
+		{"already clean", "one\ntwo", "one\ntwo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeWhisperText(tt.text); got != tt.want {
+				t.Errorf("normalizeWhisperText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostProcessTrimsAfterNormalizing(t *testing.T) {
+	got := transcribeConfig{}.postProcess("\ufeff\r\n first line\r\nsecond line\r\n")
+	if want := "first line\nsecond line"; got != want {
+		t.Errorf("postProcess() = %q, want %q", got, want)
+	}
+}
+
+func TestUniqueBaseName(t *testing.T) {
+	dir := t.TempDir()
+	touch := func(name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const base = "2026-01-02_150405"
+
+	check := func(want string, suffixes ...string) {
+		t.Helper()
+		got, release := uniqueBaseName(dir, base, suffixes...)
+		release()
+		if got != want {
+			t.Errorf("uniqueBaseName() = %q, want %q", got, want)
+		}
+	}
+
+	check(base, ".md", ".wav")
+	touch(base + ".wav")
+	check(base+"-1", ".md", ".wav")
+	touch(base + "-1.md")
+	touch(base + "-2.md")
+	check(base+"-3", ".md", ".wav")
+	// Other extensions don't count
+	check(base, ".txt")
+
+	// A name handed out but not yet written isn't reused until released
+	first, release := uniqueBaseName(dir, base, ".txt")
+	second, releaseSecond := uniqueBaseName(dir, base, ".txt")
+	if first == second {
+		t.Errorf("uniqueBaseName() returned %q twice", first)
+	}
+	release()
+	releaseSecond()
+	check(base, ".txt")
+}
+
+func TestSaveTranscriptionSameSecond(t *testing.T) {
+	home := useTempHome(t)
+	t.Setenv("XDG_DOCUMENTS_DIR", filepath.Join(home, "Documents"))
+	wavPath := filepath.Join(t.TempDir(), "meeting.wav")
+	if err := writePCMWAV(wavPath, make([]int16, 1600), 16000, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	result := transcription{Text: "hello", Segments: []Segment{{Start: 0, End: 0.1, Text: "hello"}}}
+	const saves = 4
+	paths := make([]TranscribeResult, saves)
+	errs := make([]error, saves)
+	var wg sync.WaitGroup
+	for i := range saves {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			paths[i], errs[i] = saveTranscription(transcribeConfig{}, wavPath, MeetingMeta{}, result)
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i := range saves {
+		if errs[i] != nil {
+			t.Fatalf("saveTranscription() = %v", errs[i])
+		}
+		for _, p := range append(paths[i].MarkdownPaths, paths[i].AudioPath) {
+			if seen[p] {
+				t.Errorf("%s was written by two saves", filepath.Base(p))
+			}
+			seen[p] = true
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(home, "Documents", "Transcriptions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := saves * 3; len(entries) != want {
+		t.Errorf("got %d files, want %d (markdown, audio and sidecar per save)", len(entries), want)
+	}
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// transcriptSuffix is appended to the timestamp base name of the
+// structured sidecar written next to each markdown transcript.
+const transcriptSuffix = ".transcript.json"
+
+// Segment is one timed piece of a transcript.
+type Segment struct {
+	Start   float64 `json:"start"` // seconds from the start of the recording
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"`
+	// Language is whisper's detection for the run that produced the
+	// segment when the language is "auto"; see DetectedLanguages
+	Language string `json:"language,omitempty"`
+}
+
+// TranscriptFile is the structured sidecar saved alongside the markdown.
+type TranscriptFile struct {
+	RecordingID string    `json:"recordingId,omitempty"`
+	Date        string    `json:"date"`
+	Text        string    `json:"text"`
+	Segments    []Segment `json:"segments"`
+	// SpeakerLabels is set when segments carry meaningful speaker names,
+	// either from diarization or from RelabelSpeaker.
+	SpeakerLabels bool `json:"speakerLabels"`
+	// Meta is the metadata given to TranscribeToFileWithMeta, if any
+	Meta *MeetingMeta `json:"meta,omitempty"`
+	// AudioFile is the saved recording, relative to the markdown, that
+	// segment timestamps link to; see SetAudioLinkedTimestamps
+	AudioFile string `json:"audioFile,omitempty"`
+	// AudioHash identifies the transcribed audio; see FindDuplicateRecording
+	AudioHash string `json:"audioHash,omitempty"`
+	// Part is like "2 of 3" for split transcripts; see SetOutputSplit
+	Part string `json:"part,omitempty"`
+	// LanguageTags annotates language switches; see SetLanguageTags
+	LanguageTags bool `json:"languageTags,omitempty"`
+}
+
+// meta returns the transcript's metadata, or the zero value if it has none.
+func (tf *TranscriptFile) meta() MeetingMeta {
+	if tf.Meta == nil {
+		return MeetingMeta{}
+	}
+	return *tf.Meta
+}
+
+// transcription is the result of a single whisper run.
+type transcription struct {
+	Text     string
+	Segments []Segment
+	Language string // as detected by whisper with language "auto", if known
+}
+
+// whisperJSON mirrors the parts of whisper-cpp's --output-json we use.
+type whisperJSON struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"` // milliseconds
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+		// Set by --diarize (stereo input)
+		Speaker string `json:"speaker"`
+		// Set by --tinydiarize models
+		SpeakerTurnNext bool `json:"speaker_turn_next"`
+	} `json:"transcription"`
+}
+
+// parseWhisperJSON extracts segments and the transcribed language from
+// whisper-cpp JSON output. Without diarization data every segment is
+// attributed to "Speaker 1".
+func parseWhisperJSON(data []byte) ([]Segment, string, error) {
+	var out whisperJSON
+	// encoding/json rejects a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, "", fmt.Errorf("invalid whisper JSON: %w", err)
+	}
+
+	segments := make([]Segment, 0, len(out.Transcription))
+	turn := 0
+	for _, s := range out.Transcription {
+		seg := Segment{
+			Start: float64(s.Offsets.From) / 1000,
+			End:   float64(s.Offsets.To) / 1000,
+			Text:  strings.TrimSpace(s.Text),
+		}
+		if n, err := strconv.Atoi(s.Speaker); err == nil {
+			seg.Speaker = speakerLabel(n)
+		} else {
+			seg.Speaker = speakerLabel(turn)
+		}
+		if s.SpeakerTurnNext {
+			turn++
+		}
+		segments = append(segments, seg)
+	}
+	return segments, out.Result.Language, nil
+}
+
+func speakerLabel(n int) string {
+	return fmt.Sprintf("Speaker %d", n+1)
+}
+
+// hasMultipleSpeakers reports whether segments name more than one speaker.
+func hasMultipleSpeakers(segments []Segment) bool {
+	for _, s := range segments {
+		if s.Speaker != segments[0].Speaker {
+			return true
+		}
+	}
+	return false
+}
+
+// body returns the markdown body, with consecutive segments grouped under
+// bold speaker names when the transcript has speaker labels, and a new
+// paragraph tagged with the language wherever it switches when language
+// tags are on.
+func (tf *TranscriptFile) body() string {
+	if tf.AudioFile != "" && len(tf.Segments) > 0 {
+		return tf.linkedBody()
+	}
+	tags := tf.languageTagged()
+	if (!tf.SpeakerLabels && !tags) || len(tf.Segments) == 0 {
+		return tf.Text
+	}
+
+	var sb strings.Builder
+	speaker, lang := "", ""
+	for i, s := range tf.Segments {
+		newSpeaker := tf.SpeakerLabels && (i == 0 || s.Speaker != speaker)
+		newLang := tags && s.Language != "" && s.Language != lang
+		if i > 0 {
+			if newSpeaker || newLang {
+				sb.WriteString("\n\n")
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		if newSpeaker {
+			speaker = s.Speaker
+			fmt.Fprintf(&sb, "**%s:** ", speaker)
+		}
+		if newLang {
+			lang = s.Language
+			fmt.Fprintf(&sb, "_[%s]_ ", lang)
+		}
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
+
+// languageTagged reports whether segments should be annotated with their
+// language: tags are on and at least one segment has one.
+func (tf *TranscriptFile) languageTagged() bool {
+	return tf.LanguageTags && slices.ContainsFunc(tf.Segments, func(s Segment) bool { return s.Language != "" })
+}
+
+// linkedBody renders one paragraph per segment, each starting with a
+// timestamp that links into the audio with a media fragment, e.g.
+// "[[00:01:23]](2026-01-02_150405.wav#t=83)".
+func (tf *TranscriptFile) linkedBody() string {
+	var sb strings.Builder
+	current, lang := "", ""
+	tags := tf.languageTagged()
+	for i, s := range tf.Segments {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sec := int(s.Start)
+		fmt.Fprintf(&sb, "[[%02d:%02d:%02d]](%s#t=%d) ", sec/3600, sec/60%60, sec%60, url.PathEscape(tf.AudioFile), sec)
+		if tf.SpeakerLabels && (i == 0 || s.Speaker != current) {
+			current = s.Speaker
+			fmt.Fprintf(&sb, "**%s:** ", current)
+		}
+		if tags && s.Language != "" && s.Language != lang {
+			lang = s.Language
+			fmt.Fprintf(&sb, "_[%s]_ ", lang)
+		}
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
+
+func readTranscriptFile(path string) (*TranscriptFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("transcript file not found: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+
+	var tf TranscriptFile
+	if err := json.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("transcript file is malformed: %w", err)
+	}
+	return &tf, nil
+}
+
+func writeTranscriptFile(path string, tf *TranscriptFile) error {
+	data, err := json.MarshalIndent(tf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write transcript file: %w", err)
+	}
+	return nil
+}
+
+// RelabelSpeaker renames a speaker (e.g. "Speaker 1" -> "Alice") in a
+// .transcript.json file and regenerates the sibling markdown.
+func (t *TranscribeService) RelabelSpeaker(transcriptPath, fromLabel, toName string) error {
+	if !strings.HasSuffix(transcriptPath, transcriptSuffix) {
+		return fmt.Errorf("not a transcript file: %s", transcriptPath)
+	}
+	toName = strings.TrimSpace(toName)
+	if toName == "" {
+		return fmt.Errorf("speaker name cannot be empty")
+	}
+
+	tf, err := readTranscriptFile(transcriptPath)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range tf.Segments {
+		if tf.Segments[i].Speaker == fromLabel {
+			tf.Segments[i].Speaker = toName
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("speaker %q not found in transcript", fromLabel)
+	}
+	tf.SpeakerLabels = true
+
+	if err := writeTranscriptFile(transcriptPath, tf); err != nil {
+		return err
+	}
+
+	mdPath := strings.TrimSuffix(transcriptPath, transcriptSuffix) + ".md"
+	return t.config().writeMarkdownPart(mdPath, tf.body(), tf.Date, tf.meta(), tf.Part)
+}
+
+// RegenerateMarkdown re-renders the markdown for a .transcript.json file
+// with the current template, heading, timestamp, language tag and text
+// clean-up settings, without running whisper again. The markdown is
+// overwritten or saved under a new name according to SetOnNameCollision.
+// Returns the markdown path.
+func (t *TranscribeService) RegenerateMarkdown(transcriptJSONPath string) (string, error) {
+	if !strings.HasSuffix(transcriptJSONPath, transcriptSuffix) {
+		return "", fmt.Errorf("not a transcript file: %s", transcriptJSONPath)
+	}
+	if _, err := os.Stat(transcriptJSONPath); errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no structured transcript at %s; only transcripts saved with timed segments can be regenerated", transcriptJSONPath)
+	}
+	tf, err := readTranscriptFile(transcriptJSONPath)
+	if err != nil {
+		return "", err
+	}
+
+	cfg := t.config()
+	if cfg.textPostProcess {
+		tf.Text = tidyText(tf.Text, cfg.language)
+		for i := range tf.Segments {
+			tf.Segments[i].Text = tidyText(tf.Segments[i].Text, cfg.language)
+		}
+	}
+	dir := filepath.Dir(transcriptJSONPath)
+	base := strings.TrimSuffix(filepath.Base(transcriptJSONPath), transcriptSuffix)
+	switch {
+	case !cfg.audioLinks:
+		tf.AudioFile = ""
+	case tf.AudioFile == "":
+		// Saved before links were enabled; the audio copy shares the base name
+		audioBase := transcriptBase(base)
+		for _, ext := range []string{".wav", ".flac"} {
+			if _, err := os.Stat(filepath.Join(dir, audioBase+ext)); err == nil {
+				tf.AudioFile = audioBase + ext
+				break
+			}
+		}
+	}
+	tf.LanguageTags = cfg.languageTags
+	if cfg.onCollision != collisionOverwrite {
+		var release func()
+		base, release = uniqueBaseName(dir, base, ".md")
+		defer release()
+	}
+	mdPath := filepath.Join(dir, base+".md")
+	if err := cfg.writeMarkdownPart(mdPath, tf.body(), tf.Date, tf.meta(), tf.Part); err != nil {
+		return "", err
+	}
+	return mdPath, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// SetTrimEdges drops startSec from the beginning and endSec from the end of
+// each recording when it's saved for transcription, to cut button-press
+// noise and sign-off chatter. Both default to zero. Trimming is skipped, with
+// an "audio:warning" event, for recordings shorter than the two combined.
+func (a *AudioService) SetTrimEdges(startSec, endSec float64) error {
+	if startSec < 0 || endSec < 0 {
+		return fmt.Errorf("trim must not be negative, got %g and %g seconds", startSec, endSec)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.trimStart = startSec
+	a.trimEnd = endSec
+	return nil
+}
+
+// trimEdges removes the configured margins from samples at s.sr.
+func (s audioSnapshot) trimEdges(samples []int16) []int16 {
+	start := int(s.trimStart * float64(s.sr))
+	end := int(s.trimEnd * float64(s.sr))
+	if start == 0 && end == 0 {
+		return samples
+	}
+	if start+end >= len(samples) {
+		// Saving the whole recording beats saving nothing
+		msg := fmt.Sprintf("recording is shorter than the %gs start and %gs end trim; saved it untrimmed", s.trimStart, s.trimEnd)
+		log.Print(msg)
+		go application.Get().Event.Emit("audio:warning", AudioWarning{RecordingID: s.recordingID, Message: msg})
+		return samples
+	}
+	return samples[start : len(samples)-end]
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+const (
+	// checksumSuffix names the file holding a model's SHA-256, written
+	// after a successful download
+	checksumSuffix = ".sha256"
+	hashChunkSize  = 1024 * 1024
+)
+
+// VerifyModel re-hashes a downloaded model and compares it to the checksum
+// recorded at download time. The file is hashed in chunks without holding
+// the service lock, emitting "model:download-progress" events with
+// Verifying set; CancelDownload stops it.
+func (m *ModelService) VerifyModel(name string) error {
+	model, ok := findModelDefinition(name)
+	if !ok {
+		return fmt.Errorf("unknown model: %s", name)
+	}
+
+	path := filepath.Join(m.GetModelsDir(), model.FileName)
+	want, err := os.ReadFile(path + checksumSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no checksum recorded for %s; re-download it to enable verification", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checksum: %w", err)
+	}
+
+	return m.verifyChecksum(name, path, strings.TrimSpace(string(want)), func(done, total int64) {
+		application.Get().Event.Emit("model:download-progress", DownloadProgress{
+			ModelName:   name,
+			BytesLoaded: done,
+			BytesTotal:  total,
+			Percent:     float64(done) / float64(max(total, 1)) * 100,
+			Verifying:   true,
+		})
+	})
+}
+
+// verifyChecksum hashes the model name at path and compares the result to
+// want, registering the run so CancelDownload can stop it.
+func (m *ModelService) verifyChecksum(name, path, want string, onProgress func(done, total int64)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	if _, busy := m.progress[name]; busy {
+		m.mu.Unlock()
+		cancel()
+		return fmt.Errorf("model %s is downloading", name)
+	}
+	if _, busy := m.verifyCancels[name]; busy {
+		m.mu.Unlock()
+		cancel()
+		return fmt.Errorf("model %s is already being verified", name)
+	}
+	if m.verifyCancels == nil {
+		m.verifyCancels = make(map[string]context.CancelFunc)
+	}
+	m.verifyCancels[name] = cancel
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.verifyCancels, name)
+		m.mu.Unlock()
+		cancel()
+	}()
+
+	got, err := hashFile(ctx, path, onProgress)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("verification cancelled")
+		}
+		return err
+	}
+
+	if got != want {
+		return fmt.Errorf("model %s is corrupt (checksum mismatch); delete and re-download it", name)
+	}
+	return nil
+}
+
+// hashFile returns the hex SHA-256 of path, reading it in chunks and
+// stopping early when ctx is cancelled. onProgress is called periodically.
+func hashFile(ctx context.Context, path string, onProgress func(done, total int64)) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot open model: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("cannot read model: %w", err)
+	}
+	total := info.Size()
+
+	h := sha256.New()
+	buf := make([]byte, hashChunkSize)
+	var done int64
+	lastEmit := time.Time{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, readErr := f.Read(buf)
+		h.Write(buf[:n])
+		done += int64(n)
+
+		if now := time.Now(); now.Sub(lastEmit) >= 200*time.Millisecond || readErr == io.EOF {
+			onProgress(done, total)
+			lastEmit = now
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return "", fmt.Errorf("failed to read model: %w", readErr)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// expectedSHA256 returns the SHA-256 Hugging Face reports for an LFS file in
+// the X-Linked-Etag header of the redirect that precedes the download, or ""
+// if none was sent.
+func expectedSHA256(resp *http.Response) string {
+	for r := resp; r != nil; {
+		etag := strings.Trim(strings.TrimPrefix(r.Header.Get("X-Linked-Etag"), "W/"), `"`)
+		if len(etag) == sha256.Size*2 {
+			if _, err := hex.DecodeString(etag); err == nil {
+				return strings.ToLower(etag)
+			}
+		}
+		if r.Request == nil {
+			break
+		}
+		r = r.Request.Response
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyChecksumLeavesServiceResponsive(t *testing.T) {
+	data := make([]byte, 3*hashChunkSize)
+	path := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+
+	var m ModelService
+	calls := 0
+	err := m.verifyChecksum("base", path, hex.EncodeToString(sum[:]), func(done, total int64) {
+		calls++
+		// Called mid-hash on the verifying goroutine; a held lock would
+		// block these until verification finished
+		answered := make(chan struct{})
+		go func() {
+			m.IsDownloading()
+			close(answered)
+		}()
+		select {
+		case <-answered:
+		case <-time.After(time.Second):
+			t.Fatal("IsDownloading blocked while a model was being verified")
+		}
+	})
+	if err != nil {
+		t.Fatalf("verifyChecksum() = %v", err)
+	}
+	if calls == 0 {
+		t.Fatal("progress was never reported")
+	}
+}
+
+func TestVerifyChecksumMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(path, []byte("model"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var m ModelService
+	err := m.verifyChecksum("base", path, strings.Repeat("0", 64), func(done, total int64) {})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("verifyChecksum() = %v, want a checksum mismatch", err)
+	}
+}
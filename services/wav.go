@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// writePCMWAV writes 16-bit PCM samples as a WAV file. Multi-channel
+// samples must be interleaved.
+func writePCMWAV(wavPath string, samples []int16, sampleRate, numChannels int) error {
+	return writePCMWAVBits(wavPath, samples, sampleRate, numChannels, bitDepth)
+}
+
+// writePCMWAVBits writes samples as 16- or 24-bit PCM. For 24-bit output the
+// 16-bit samples are widened, so the extra low byte is zero. The file is
+// synced and closed before returning, so readers that open it right away
+// (such as whisper) see the complete file.
+func writePCMWAVBits(wavPath string, samples []int16, sampleRate, numChannels, bits int) error {
+	f, err := os.Create(wavPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bytesPerSample := bits / 8
+	dataSize := uint32(len(samples) * bytesPerSample)
+	fileSize := 36 + dataSize
+
+	var header bytes.Buffer
+	// RIFF header
+	header.WriteString("RIFF")
+	binary.Write(&header, binary.LittleEndian, fileSize)
+	header.WriteString("WAVE")
+
+	// fmt sub-chunk
+	header.WriteString("fmt ")
+	binary.Write(&header, binary.LittleEndian, uint32(16))                                    // sub-chunk size
+	binary.Write(&header, binary.LittleEndian, uint16(1))                                     // PCM format
+	binary.Write(&header, binary.LittleEndian, uint16(numChannels))                           // channels
+	binary.Write(&header, binary.LittleEndian, uint32(sampleRate))                            // sample rate
+	binary.Write(&header, binary.LittleEndian, uint32(sampleRate*numChannels*bytesPerSample)) // byte rate
+	binary.Write(&header, binary.LittleEndian, uint16(numChannels*bytesPerSample))            // block align
+	binary.Write(&header, binary.LittleEndian, uint16(bits))                                  // bits per sample
+
+	// data sub-chunk
+	header.WriteString("data")
+	binary.Write(&header, binary.LittleEndian, dataSize)
+	if _, err := f.Write(header.Bytes()); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if bits == 24 {
+		_, err = w.Write(packInt24(samples))
+	} else {
+		err = binary.Write(w, binary.LittleEndian, samples)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// packInt24 encodes samples as little-endian 24-bit PCM.
+func packInt24(samples []int16) []byte {
+	out := make([]byte, len(samples)*3)
+	for i, s := range samples {
+		v := int32(s) << 8
+		out[i*3] = byte(v)
+		out[i*3+1] = byte(v >> 8)
+		out[i*3+2] = byte(v >> 16)
+	}
+	return out
+}
+
+// WAV format tags
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatExtensible = 0xFFFE
+)
+
+// wavInfo describes the audio in a WAV file and where its samples are.
+type wavInfo struct {
+	formatTag     uint16 // the subformat for WAVE_FORMAT_EXTENSIBLE
+	numChannels   int
+	sampleRate    int
+	byteRate      int
+	blockAlign    int
+	bitsPerSample int
+	dataOffset    int64
+	dataSize      int64
+}
+
+// readWAVHeader walks the RIFF chunk list of a WAV file until it has found
+// both the fmt and data chunks, skipping anything else (LIST, JUNK, bext,
+// ...). A data size that is missing or runs past the end of the file, as
+// left by recorders that crashed before finishing the header, is clamped to
+// the bytes actually present.
+func readWAVHeader(f *os.File) (wavInfo, error) {
+	var info wavInfo
+
+	fi, err := f.Stat()
+	if err != nil {
+		return info, err
+	}
+	fileSize := fi.Size()
+
+	riff := make([]byte, 12)
+	if _, err := io.ReadFull(f, riff); err != nil {
+		return info, fmt.Errorf("invalid WAV header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return info, fmt.Errorf("not a WAV file")
+	}
+
+	var haveFmt, haveData bool
+	offset := int64(12)
+	chunk := make([]byte, 8)
+	for !(haveFmt && haveData) {
+		if _, err := f.ReadAt(chunk, offset); err != nil {
+			break
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		offset += 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return info, fmt.Errorf("invalid WAV fmt chunk size %d", size)
+			}
+			body := make([]byte, min(size, 40))
+			if _, err := f.ReadAt(body, offset); err != nil {
+				return info, fmt.Errorf("invalid WAV fmt chunk: %w", err)
+			}
+			info.formatTag = binary.LittleEndian.Uint16(body[0:2])
+			info.numChannels = int(binary.LittleEndian.Uint16(body[2:4]))
+			info.sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			info.byteRate = int(binary.LittleEndian.Uint32(body[8:12]))
+			info.blockAlign = int(binary.LittleEndian.Uint16(body[12:14]))
+			info.bitsPerSample = int(binary.LittleEndian.Uint16(body[14:16]))
+			if info.formatTag == wavFormatExtensible {
+				if len(body) < 40 {
+					return info, fmt.Errorf("invalid WAV extensible fmt chunk")
+				}
+				// The subformat GUID starts with the real format tag
+				info.formatTag = binary.LittleEndian.Uint16(body[24:26])
+			}
+			haveFmt = true
+		case "data":
+			info.dataOffset = offset
+			if size == 0 || offset+size > fileSize {
+				size = fileSize - offset
+			}
+			info.dataSize = size
+			haveData = true
+		}
+
+		// Chunks are padded to an even length
+		offset += size + size%2
+	}
+
+	if !haveFmt {
+		return info, fmt.Errorf("invalid WAV file: no fmt chunk")
+	}
+	if !haveData {
+		return info, fmt.Errorf("invalid WAV file: no data chunk")
+	}
+	if info.formatTag != wavFormatPCM {
+		return info, fmt.Errorf("unsupported WAV encoding (format 0x%04x); only uncompressed PCM is supported. Convert it first, e.g.: ffmpeg -i input.wav -c:a pcm_s16le output.wav", info.formatTag)
+	}
+	switch info.bitsPerSample {
+	case 8, 16, 24, 32:
+	default:
+		return info, fmt.Errorf("unsupported WAV bit depth %d", info.bitsPerSample)
+	}
+	if info.numChannels < 1 || info.sampleRate < 1 {
+		return info, fmt.Errorf("invalid WAV format: %d channels at %dHz", info.numChannels, info.sampleRate)
+	}
+	// Some writers leave these zero; they follow from the rest of the format
+	info.blockAlign = info.numChannels * info.bitsPerSample / 8
+	info.byteRate = info.sampleRate * info.blockAlign
+	return info, nil
+}
+
+// readWAV reads a PCM WAV file as interleaved 16-bit samples. Other bit
+// depths are converted, keeping the most significant bits.
+func readWAV(path string) ([]int16, wavInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, wavInfo{}, err
+	}
+	defer f.Close()
+
+	info, err := readWAVHeader(f)
+	if err != nil {
+		return nil, info, err
+	}
+
+	// Drop a trailing partial frame
+	size := info.dataSize - info.dataSize%int64(info.blockAlign)
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, info.dataOffset); err != nil && err != io.EOF {
+		return nil, info, fmt.Errorf("failed to read WAV data: %w", err)
+	}
+
+	bytesPerSample := info.bitsPerSample / 8
+	samples := make([]int16, len(data)/bytesPerSample)
+	for i := range samples {
+		b := data[i*bytesPerSample:]
+		switch bytesPerSample {
+		case 1:
+			// 8-bit WAV is unsigned
+			samples[i] = int16(int(b[0])-128) << 8
+		default:
+			// Take the top two bytes of the little-endian sample
+			samples[i] = int16(binary.LittleEndian.Uint16(b[bytesPerSample-2:]))
+		}
+	}
+	return samples, info, nil
+}
+
+// validateWAV checks that path is a WAV file whisper can read, so a bad
+// import fails with a clear message instead of whisper's.
+func validateWAV(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := readWAVHeader(f); err != nil {
+		return fmt.Errorf("cannot read %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// wavDuration returns the length in seconds of a PCM WAV file.
+func wavDuration(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := readWAVHeader(f)
+	if err != nil {
+		return 0, err
+	}
+	return float64(info.dataSize) / float64(info.byteRate), nil
+}
+
+// RepairWAV fixes the RIFF and data chunk sizes of a WAV file that was cut
+// off mid-write, e.g. by a crash, so players that trust the header can open
+// it. The data size is set to the audio actually on disk, less any partial
+// trailing frame, which is dropped. Files without a fmt or data chunk can't
+// be repaired.
+func (a *AudioService) RepairWAV(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	info, err := readWAVHeader(f)
+	if err != nil {
+		return fmt.Errorf("cannot repair %s: %w", filepath.Base(path), err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	fileSize := fi.Size()
+
+	// Audio that runs to the end of the file may end mid-frame; chunks
+	// after the data, if any, show the data itself was written completely
+	if info.dataOffset+info.dataSize == fileSize {
+		info.dataSize -= info.dataSize % int64(info.blockAlign)
+		fileSize = info.dataOffset + info.dataSize
+		if err := f.Truncate(fileSize); err != nil {
+			return fmt.Errorf("failed to trim partial frame: %w", err)
+		}
+	}
+	if fileSize-8 > math.MaxUint32 {
+		return fmt.Errorf("cannot repair %s: too large for a WAV file", filepath.Base(path))
+	}
+
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(fileSize-8))
+	if _, err := f.WriteAt(size[:], 4); err != nil {
+		return fmt.Errorf("failed to write RIFF size: %w", err)
+	}
+	binary.LittleEndian.PutUint32(size[:], uint32(info.dataSize))
+	if _, err := f.WriteAt(size[:], info.dataOffset-4); err != nil {
+		return fmt.Errorf("failed to write data size: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to save %s: %w", filepath.Base(path), err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := readWAVHeader(f); err != nil {
+		return fmt.Errorf("%s is still unreadable after repair: %w", filepath.Base(path), err)
+	}
+	if info.dataSize == 0 {
+		return fmt.Errorf("%s contains no audio", filepath.Base(path))
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// riffChunk encodes one chunk with the given declared size, padding the
+// body to an even length.
+func riffChunk(id string, size uint32, body []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(id)
+	binary.Write(&buf, binary.LittleEndian, size)
+	buf.Write(body)
+	if len(body)%2 == 1 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// fmtBody returns a 16-byte fmt chunk body.
+func fmtBody(formatTag, channels uint16, sampleRate uint32, bits uint16) []byte {
+	var buf bytes.Buffer
+	align := channels * bits / 8
+	binary.Write(&buf, binary.LittleEndian, formatTag)
+	binary.Write(&buf, binary.LittleEndian, channels)
+	binary.Write(&buf, binary.LittleEndian, sampleRate)
+	binary.Write(&buf, binary.LittleEndian, sampleRate*uint32(align))
+	binary.Write(&buf, binary.LittleEndian, align)
+	binary.Write(&buf, binary.LittleEndian, bits)
+	return buf.Bytes()
+}
+
+// extensibleBody returns a 40-byte WAVE_FORMAT_EXTENSIBLE fmt body whose
+// subformat GUID starts with subFormat.
+func extensibleBody(subFormat, channels uint16, sampleRate uint32, bits uint16) []byte {
+	body := fmtBody(wavFormatExtensible, channels, sampleRate, bits)
+	ext := make([]byte, 24)
+	binary.LittleEndian.PutUint16(ext[0:2], 22) // cbSize
+	binary.LittleEndian.PutUint16(ext[2:4], bits)
+	binary.LittleEndian.PutUint16(ext[8:10], subFormat)
+	return append(body, ext...)
+}
+
+func riffFile(chunks ...[]byte) []byte {
+	body := bytes.Join(chunks, nil)
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+len(body)))
+	buf.WriteString("WAVE")
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+func readWAVHeaderBytes(t *testing.T, data []byte) (wavInfo, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.wav")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	return readWAVHeader(f)
+}
+
+func TestReadWAVHeader(t *testing.T) {
+	pcm := fmtBody(wavFormatPCM, 1, 16000, 16)
+	audio := make([]byte, 100)
+	tests := []struct {
+		name       string
+		data       []byte
+		wantOffset int64
+		wantSize   int64
+		wantTag    uint16
+	}{
+		{
+			name:       "plain",
+			data:       riffFile(riffChunk("fmt ", 16, pcm), riffChunk("data", 100, audio)),
+			wantOffset: 44, wantSize: 100, wantTag: wavFormatPCM,
+		},
+		{
+			name: "skips odd-sized chunks before data",
+			data: riffFile(riffChunk("fmt ", 16, pcm), riffChunk("LIST", 3, []byte("abc")),
+				riffChunk("data", 100, audio)),
+			wantOffset: 56, wantSize: 100, wantTag: wavFormatPCM,
+		},
+		{
+			name:       "data before fmt",
+			data:       riffFile(riffChunk("data", 100, audio), riffChunk("fmt ", 16, pcm)),
+			wantOffset: 20, wantSize: 100, wantTag: wavFormatPCM,
+		},
+		{
+			name:       "zero data size from a crashed recorder",
+			data:       riffFile(riffChunk("fmt ", 16, pcm), riffChunk("data", 0, audio)),
+			wantOffset: 44, wantSize: 100, wantTag: wavFormatPCM,
+		},
+		{
+			name:       "data size past end of file",
+			data:       riffFile(riffChunk("fmt ", 16, pcm), riffChunk("data", 0xFFFFFFFF, audio)),
+			wantOffset: 44, wantSize: 100, wantTag: wavFormatPCM,
+		},
+		{
+			name:       "extensible pcm",
+			data:       riffFile(riffChunk("fmt ", 40, extensibleBody(wavFormatPCM, 2, 48000, 24)), riffChunk("data", 100, audio)),
+			wantOffset: 68, wantSize: 100, wantTag: wavFormatPCM,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := readWAVHeaderBytes(t, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.dataOffset != tt.wantOffset || info.dataSize != tt.wantSize || info.formatTag != tt.wantTag {
+				t.Errorf("readWAVHeader() = offset %d, size %d, format %#x; want offset %d, size %d, format %#x",
+					info.dataOffset, info.dataSize, info.formatTag, tt.wantOffset, tt.wantSize, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestReadWAVHeaderMalformed(t *testing.T) {
+	pcm := fmtBody(wavFormatPCM, 1, 16000, 16)
+	data := riffChunk("data", 4, make([]byte, 4))
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"too short", []byte("RIFF"), "invalid WAV header"},
+		{"not riff", append([]byte("RIFX\x00\x00\x00\x00WAVE"), data...), "not a WAV file"},
+		{"no fmt", riffFile(data), "no fmt chunk"},
+		{"no data", riffFile(riffChunk("fmt ", 16, pcm)), "no data chunk"},
+		{"fmt too small", riffFile(riffChunk("fmt ", 14, pcm[:14]), data), "fmt chunk size 14"},
+		{"fmt size past end of file", riffFile(riffChunk("fmt ", 40, pcm)), "invalid WAV fmt chunk"},
+		{"chunk size past end hides data", riffFile(riffChunk("fmt ", 16, pcm), riffChunk("JUNK", 0xFFFFFFF0, nil), data), "no data chunk"},
+		{"truncated extensible", riffFile(riffChunk("fmt ", 18, append(fmtBody(wavFormatExtensible, 1, 16000, 16), 0, 0)), data), "extensible fmt chunk"},
+		{"extensible float", riffFile(riffChunk("fmt ", 40, extensibleBody(3, 1, 16000, 32)), data), "format 0x0003"},
+		{"compressed", riffFile(riffChunk("fmt ", 16, fmtBody(0x0011, 1, 16000, 4)), data), "unsupported WAV encoding"},
+		{"odd bit depth", riffFile(riffChunk("fmt ", 16, fmtBody(wavFormatPCM, 1, 16000, 12)), data), "bit depth 12"},
+		{"no channels", riffFile(riffChunk("fmt ", 16, fmtBody(wavFormatPCM, 0, 16000, 16)), data), "0 channels"},
+		{"no sample rate", riffFile(riffChunk("fmt ", 16, fmtBody(wavFormatPCM, 1, 0, 16)), data), "at 0Hz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readWAVHeaderBytes(t, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readWAVHeader() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWritePCMWAV24Bit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	samples := []int16{0, 1, -1, 32767, -32768, 0x1234}
+	if err := writePCMWAVBits(path, samples, 48000, 2, 24); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(data), 44+len(samples)*3; got != want {
+		t.Fatalf("file is %d bytes, want %d", got, want)
+	}
+	// The low byte is zero and the sample sits in the top 16 bits
+	if got, want := data[44+5*3:], []byte{0x00, 0x34, 0x12}; !bytes.Equal(got, want) {
+		t.Errorf("last sample bytes = % x, want % x", got, want)
+	}
+	if got, want := data[44+4*3:44+5*3], []byte{0x00, 0x00, 0x80}; !bytes.Equal(got, want) {
+		t.Errorf("-32768 bytes = % x, want % x", got, want)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, err := readWAVHeader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := wavInfo{
+		formatTag:     wavFormatPCM,
+		numChannels:   2,
+		sampleRate:    48000,
+		byteRate:      48000 * 2 * 3,
+		blockAlign:    6,
+		bitsPerSample: 24,
+		dataOffset:    44,
+		dataSize:      int64(len(samples) * 3),
+	}
+	if info != want {
+		t.Errorf("readWAVHeader() = %+v, want %+v", info, want)
+	}
+	if riffSize := binary.LittleEndian.Uint32(data[4:8]); int(riffSize) != len(data)-8 {
+		t.Errorf("RIFF size = %d, want %d", riffSize, len(data)-8)
+	}
+
+	got, _, err := readWAV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, samples) {
+		t.Errorf("readWAV() = %v, want %v", got, samples)
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import "github.com/wailsapp/wails/v3/pkg/application"
+
+// waveformChunkPeaks is how many callback peaks go into each
+// "audio:waveform-chunk" event, around 0.2s of audio at common rates.
+const waveformChunkPeaks = 8
+
+// WaveformChunk is emitted as "audio:waveform-chunk" while recording, for a
+// live scrolling waveform. Each peak is the loudest sample (0-1) of one
+// input buffer.
+type WaveformChunk struct {
+	RecordingID string    `json:"recordingId"`
+	Index       int       `json:"index"` // of the first peak since recording started
+	Peaks       []float64 `json:"peaks"`
+	// PeakSeconds is the audio duration each peak covers
+	PeakSeconds float64 `json:"peakSeconds"`
+}
+
+// trackWaveform adds the latest buffer's peak, already measured by
+// trackLevel, and emits a chunk once enough have built up. It's skipped in
+// low-power mode along with the spectrum. Callers must hold a.mu.
+func (a *AudioService) trackWaveform(frames int) {
+	if a.lowPower {
+		return
+	}
+	a.wavePeaks = append(a.wavePeaks, a.levelPeak)
+	if len(a.wavePeaks) < waveformChunkPeaks {
+		return
+	}
+	chunk := WaveformChunk{
+		RecordingID: a.recordingID,
+		Index:       a.waveIndex,
+		Peaks:       a.wavePeaks,
+		PeakSeconds: float64(frames) / a.nativeSR,
+	}
+	a.waveIndex += len(a.wavePeaks)
+	a.wavePeaks = make([]float64, 0, waveformChunkPeaks)
+	// Emit after the lock is released so listeners can call back in
+	go application.Get().Event.Emit("audio:waveform-chunk", chunk)
+}
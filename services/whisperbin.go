@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Whisper binary variants, in order of preference
+const (
+	whisperVariantCLI    = "whisper-cli" // current whisper.cpp CLI
+	whisperVariantCPP    = "whisper-cpp" // older Homebrew name for the same CLI
+	whisperVariantLegacy = "legacy"      // the original whisper.cpp "main" example
+)
+
+// whisperProbeTimeout bounds help-output runs used to identify binaries.
+const whisperProbeTimeout = 5 * time.Second
+
+// findWhisperBinary locates the best installed whisper binary and reports
+// which variant it is. Legacy names like "main" and "whisper" are generic,
+// so they're only accepted if their help output looks like whisper.cpp.
+func findWhisperBinary() (path, variant string) {
+	if p := findExecutable("whisper-cli"); p != "" {
+		return p, whisperVariantCLI
+	}
+	if p := findExecutable("whisper-cpp"); p != "" {
+		return p, whisperVariantCPP
+	}
+	for _, name := range []string{"main", "whisper"} {
+		if p := findExecutable(name); p != "" && isLegacyWhisperCpp(p) {
+			return p, whisperVariantLegacy
+		}
+	}
+	return "", ""
+}
+
+// isLegacyWhisperCpp reports whether the binary at path is whisper.cpp's old
+// main example, as opposed to e.g. the Python openai-whisper CLI.
+func isLegacyWhisperCpp(path string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), whisperProbeTimeout)
+	defer cancel()
+	// main prints usage and may exit non-zero, so only the output matters
+	out, _ := exec.CommandContext(ctx, path, "-h").CombinedOutput()
+	return strings.Contains(string(out), "-otxt")
+}
+
+// outputFormatFlags maps each output format to the flag that enables it.
+// txt is always available.
+var outputFormatFlags = []struct {
+	format, flag string
+}{
+	{"srt", "--output-srt"},
+	{"vtt", "--output-vtt"},
+	{"json", "--output-json"},
+	{"json-full", "--output-json-full"},
+}
+
+// SupportedOutputFormats returns which of txt, srt, vtt, json and json-full
+// the installed whisper binary can write, so the UI only offers those. The
+// binary's help output is probed once; legacy or unknown binaries report
+// only txt.
+func (t *TranscribeService) SupportedOutputFormats() []string {
+	formats := []string{"txt"}
+	for _, f := range outputFormatFlags {
+		if t.supportsFlag(f.flag) {
+			formats = append(formats, f.format)
+		}
+	}
+	return formats
+}
+
+// supportsFlag reports whether the whisper binary's help output lists flag.
+func (t *TranscribeService) supportsFlag(flag string) bool {
+	t.probeHelp()
+	return t.helpFlags[flag]
+}
+
+// writesJSON reports whether to ask whisper for JSON output, which carries
+// the segment timings. When the help probe found no flags at all, because it
+// failed or timed out or the binary is legacy, JSON is requested anyway as
+// it always was before probing.
+func (t *TranscribeService) writesJSON() bool {
+	t.probeHelp()
+	return len(t.helpFlags) == 0 || t.helpFlags["--output-json"]
+}
+
+// probeHelp runs the whisper binary's --help once and caches the result.
+func (t *TranscribeService) probeHelp() {
+	t.helpOnce.Do(func() {
+		t.helpFlags, t.helpText = probeHelpFlags(t.whisperBin, t.whisperVariant)
+	})
+}
+
+// probeHelpFlags returns the long flags listed by the binary's --help, along
+// with the full help text. Legacy binaries are treated as having none, since
+// their options differ.
+func probeHelpFlags(bin, variant string) (map[string]bool, string) {
+	flags := make(map[string]bool)
+	if bin == "" || variant == whisperVariantLegacy {
+		return flags, ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), whisperProbeTimeout)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, bin, "--help").CombinedOutput()
+
+	// Compare whole flags so --output-json doesn't match --output-json-full
+	for _, f := range strings.Fields(string(out)) {
+		if strings.HasPrefix(f, "--") {
+			flags[strings.TrimRight(f, ",")] = true
+		}
+	}
+	return flags, string(out)
+}
+
+// whisperArgs returns the command line for transcribing wavPath with the
+// given binary variant. JSON output, used for segment timings, is only
+// requested with writeJSON; see writesJSON. extra flags go before the input.
+func whisperArgs(variant, modelPath, language, wavPath string, writeJSON bool, extra ...string) []string {
+	if variant == whisperVariantLegacy {
+		// Old builds only reliably know the short flags and have no --no-prints
+		args := []string{
+			"-m", modelPath,
+			"-l", language,
+			"-otxt",
+		}
+		if writeJSON {
+			args = append(args, "-oj")
+		}
+		args = append(args, extra...)
+		return append(args, wavPath)
+	}
+	args := []string{
+		"--model", modelPath,
+		"--language", language,
+		"--output-txt",
+	}
+	if writeJSON {
+		args = append(args, "--output-json")
+	}
+	args = append(args, extra...)
+	return append(args, "--no-prints", wavPath)
+}
+
+// whisperOutputPath returns the file whisper wrote for inputPath with the
+// given extension. Depending on version it's <input>.ext or, with the input's
+// extension replaced, <name>.ext; the first that exists wins. If neither does
+// the appended form is returned, so reading it fails as before.
+func whisperOutputPath(inputPath, ext string) string {
+	appended := inputPath + "." + ext
+	replaced := strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + "." + ext
+	for _, p := range []string{appended, replaced} {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return appended
+}
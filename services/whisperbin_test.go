@@ -0,0 +1,34 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWhisperOutputPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		create []string
+		want   string
+	}{
+		{"appended", []string{"x.wav.txt"}, "x.wav.txt"},
+		{"replaced", []string{"x.txt"}, "x.txt"},
+		{"both prefers appended", []string{"x.txt", "x.wav.txt"}, "x.wav.txt"},
+		{"neither", nil, "x.wav.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.create {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := whisperOutputPath(filepath.Join(dir, "x.wav"), "txt")
+			if want := filepath.Join(dir, tt.want); got != want {
+				t.Errorf("whisperOutputPath() = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	// whisperLogLines is how much output LastWhisperOutput keeps
+	whisperLogLines = 100
+	// whisperErrorLines is how much output is quoted in error messages
+	whisperErrorLines = 5
+)
+
+// whisperLog holds the tail of the most recent whisper run. It has its own
+// lock because runs happen on workflow and batch goroutines.
+type whisperLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// set replaces the log with the last whisperLogLines lines of output.
+func (l *whisperLog) set(output []byte) {
+	lines := tailLines(string(output), whisperLogLines)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = lines
+}
+
+func (l *whisperLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.lines, "\n")
+}
+
+// LastWhisperOutput returns the last lines whisper printed on its most
+// recent run, for showing in a details view when a transcription fails.
+func (t *TranscribeService) LastWhisperOutput() string {
+	return t.whisperLog.String()
+}
+
+// tailLines returns the last n lines of text, ignoring trailing newlines.
+func tailLines(text string, n int) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// outputTail formats the end of whisper's output for an error message.
+func outputTail(output []byte) string {
+	return strings.Join(tailLines(string(output), whisperErrorLines), "\n")
+}
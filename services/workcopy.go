@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SetTranscribeOnCopy controls whether whisper runs on a copy of the audio
+// in a private temporary directory, where it can always write its output,
+// rather than next to the original. It's on by default; turning it off
+// saves the copy but fails for read-only or synced folders whisper can't
+// write to.
+func (t *TranscribeService) SetTranscribeOnCopy(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg.noWorkCopy = !enabled
+}
+
+// workingCopy links or copies path into a new temporary directory and
+// returns the copy. cleanup removes the directory along with whatever
+// whisper wrote there.
+func workingCopy(path string) (copyPath string, cleanup func(), err error) {
+	dir, err := os.MkdirTemp("", "meeting_whisper_*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	copyPath = filepath.Join(dir, filepath.Base(path))
+	// A hard link is free when the temp directory is on the same volume
+	if os.Link(path, copyPath) == nil {
+		return copyPath, cleanup, nil
+	}
+	if err := copyFile(path, copyPath); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to copy audio to working directory: %w", err)
+	}
+	return copyPath, cleanup, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// Workflow stages reported in "workflow:progress"
+const (
+	stageTranscribing = "transcribing"
+	stageDone         = "done"
+	stageFailed       = "failed"
+)
+
+// WorkflowProgress is emitted as "workflow:progress" while a recording is
+// transcribed and saved by TranscribeRecording.
+type WorkflowProgress struct {
+	RecordingID  string `json:"recordingId"`
+	Stage        string `json:"stage"`
+	WavPath      string `json:"wavPath"`
+	MarkdownPath string `json:"markdownPath,omitempty"` // set when done
+	Error        string `json:"error,omitempty"`        // set when failed; the audio is kept
+}
+
+// WorkflowService chains recording and transcription, e.g. to transcribe
+// automatically when a recording stops.
+type WorkflowService struct {
+	audio      *AudioService
+	transcribe *TranscribeService
+
+	mu             sync.Mutex
+	autoTranscribe bool
+}
+
+func NewWorkflowService(audio *AudioService, transcribe *TranscribeService) *WorkflowService {
+	w := &WorkflowService{audio: audio, transcribe: transcribe}
+	audio.onRecordingStopped(w.recordingStopped)
+	return w
+}
+
+func (w *WorkflowService) ServiceName() string {
+	return "WorkflowService"
+}
+
+func (w *WorkflowService) ServiceStartup(_ context.Context, _ application.ServiceOptions) error {
+	return nil
+}
+
+func (w *WorkflowService) ServiceShutdown() error {
+	return nil
+}
+
+// SetAutoTranscribe makes StopRecording start TranscribeRecording in the
+// background. StopRecording still returns as soon as the audio is saved;
+// follow "workflow:progress" for the result.
+func (w *WorkflowService) SetAutoTranscribe(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.autoTranscribe = enabled
+}
+
+// TranscribeRecording transcribes a recording made this session and saves
+// the markdown, reporting each stage as "workflow:progress". A recording
+// split with SetSegmentOnResume is transcribed one segment at a time, each
+// to its own markdown, and the first is returned; segments below
+// SetMinTranscribeLength are skipped. On failure the recorded audio is left
+// in place so it can be transcribed again.
+func (w *WorkflowService) TranscribeRecording(recordingID string) (string, error) {
+	info, ok := recordings.get(recordingID)
+	if !ok {
+		return "", fmt.Errorf("unknown recording: %s", recordingID)
+	}
+	wavPaths := info.SegmentPaths
+	if len(wavPaths) == 0 {
+		if info.WavPath == "" {
+			return "", fmt.Errorf("recording %s has no saved audio yet", recordingID)
+		}
+		wavPaths = []string{info.WavPath}
+	}
+
+	var first string
+	for _, wavPath := range wavPaths {
+		emit := func(p WorkflowProgress) {
+			p.RecordingID = recordingID
+			p.WavPath = wavPath
+			application.Get().Event.Emit("workflow:progress", p)
+		}
+
+		emit(WorkflowProgress{Stage: stageTranscribing})
+		mdPath, err := w.transcribe.TranscribeToFile(wavPath)
+		if err != nil {
+			emit(WorkflowProgress{Stage: stageFailed, Error: err.Error()})
+			if len(wavPaths) > 1 && errors.Is(err, ErrBelowMinLength) {
+				// A brief stretch between pauses shouldn't hold up the rest
+				continue
+			}
+			return "", err
+		}
+		emit(WorkflowProgress{Stage: stageDone, MarkdownPath: mdPath})
+		if first == "" {
+			first = mdPath
+		}
+	}
+	if first == "" {
+		return "", fmt.Errorf("%w: every segment of recording %s", ErrBelowMinLength, recordingID)
+	}
+	return first, nil
+}
+
+func (w *WorkflowService) recordingStopped(info RecordingInfo) {
+	w.mu.Lock()
+	auto := w.autoTranscribe
+	w.mu.Unlock()
+	if auto {
+		// Errors are reported through the progress event
+		w.TranscribeRecording(info.ID)
+	}
+}